@@ -1,27 +1,71 @@
 package agent
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/d1nch8g/g8t/config"
 	"github.com/d1nch8g/g8t/gpt"
 	"github.com/d1nch8g/g8t/logger"
+	"github.com/d1nch8g/g8t/redact"
+	"github.com/d1nch8g/g8t/risk"
 )
 
 type Agent struct {
-	config    *Config
-	logger    *logger.Logger
-	gptClient gpt.Client
-	history   *History
-	stepCount int
-	startTime time.Time
+	config      *Config
+	logger      logger.Logger
+	gptClient   gpt.Client
+	attachments []gpt.Attachment
+	history     *History
+	steps       []Step
+	result      Result
+	answer      string
+	stepCount   int
+	startTime   time.Time
+	usage       gpt.Usage
+	gate        Gate
+	// redactor masks secrets before they reach the model or logs, nil
+	// when redaction is disabled
+	redactor *redact.Redactor
 }
 
+// Decision tells the agent what to do with a proposed command
+type Decision int
+
+const (
+	DecisionRun Decision = iota
+	// DecisionApprove runs the command as well and records that the
+	// user approved it
+	DecisionApprove
+	DecisionSkip
+	DecisionAbort
+)
+
+// Proposal is a command the agent is about to run
+type Proposal struct {
+	Command string
+	Thought string
+	Risk    risk.Assessment
+	// Approval is set when policy requires the user to approve the
+	// command, the gate must not let it run without asking
+	Approval bool
+}
+
+// Gate is consulted before every command, it may block until the user
+// makes up their mind
+type Gate func(p Proposal) Decision
+
+// ErrAborted is returned by runs stopped by the user
+var ErrAborted = errors.New("aborted by user")
+
 type Config struct {
 	*config.Config
 }
@@ -30,15 +74,38 @@ type Step struct {
 	Number    int       `json:"number"`
 	Timestamp time.Time `json:"timestamp"`
 	Thought   string    `json:"thought"`
+	Reasoning string    `json:"reasoning,omitempty"`
 	Command   string    `json:"command"`
 	Output    string    `json:"output"`
 	Error     string    `json:"error"`
 	Success   bool      `json:"success"`
+	Risk      string    `json:"risk,omitempty"`
+	// Duration of the command, zero when it was not executed
+	Duration time.Duration `json:"duration,omitempty"`
+}
+
+// Result is the outcome the model reports when it completes the task
+type Result struct {
+	Summary      string   `json:"summary"`
+	FilesChanged []string `json:"files_changed,omitempty"`
+	FollowUps    []string `json:"follow_ups,omitempty"`
+	// Data is output the task asked for, any JSON value
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Stats summarizes resources spent by a run
+type Stats struct {
+	Steps    int
+	Usage    gpt.Usage
+	Duration time.Duration
 }
 
 type History struct {
 	Steps    []Step `json:"steps"`
 	MaxSteps int    `json:"max_steps"`
+	// OutputLimit keeps up to this many last bytes of each output in
+	// context, outputs are left out when it is zero
+	OutputLimit int `json:"output_limit,omitempty"`
 }
 
 func NewHistory(maxSteps int) *History {
@@ -68,6 +135,18 @@ func (h *History) GetContext() string {
 		context.WriteString(fmt.Sprintf("\nStep %d:\n", step.Number))
 		context.WriteString(fmt.Sprintf("Thought: %s\n", step.Thought))
 		context.WriteString(fmt.Sprintf("Command: %s\n", step.Command))
+		if h.OutputLimit > 0 && step.Output != "" {
+			output := step.Output
+			if len(output) > h.OutputLimit {
+				// Start at a whole character, models reject invalid UTF-8
+				start := len(output) - h.OutputLimit
+				for start < len(output) && !utf8.RuneStart(output[start]) {
+					start++
+				}
+				output = "..." + output[start:]
+			}
+			context.WriteString(fmt.Sprintf("Output:\n%s\n", strings.TrimRight(output, "\n")))
+		}
 		if step.Error != "" {
 			context.WriteString(fmt.Sprintf("Error: %s\n", step.Error))
 		}
@@ -77,112 +156,307 @@ func (h *History) GetContext() string {
 	return context.String()
 }
 
-func New(cfg *config.Config, log *logger.Logger) (*Agent, error) {
+func New(cfg *config.Config, log logger.Logger) (*Agent, error) {
 	// Create GPT client based on provider
 	gptClient, err := createGPTClient(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GPT client: %w", err)
 	}
 
+	return NewWithClient(cfg, log, gptClient)
+}
+
+// NewWithClient creates agent talking to given client instead of the one
+// configured by provider settings, used by tests and embedding programs
+func NewWithClient(cfg *config.Config, log logger.Logger, gptClient gpt.Client) (*Agent, error) {
+	if _, err := cfg.Timeout(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+
+	if provider, ok := gpt.Lookup(cfg.Provider); ok {
+		for _, option := range provider.Unsupported(cfg.ProviderSettings(cfg.Provider), cfg.GenerationOptions(cfg.Provider)) {
+			log.Warning("Option %s is not supported by %s provider and will be ignored", option, cfg.Provider)
+		}
+	}
+
+	var redactor *redact.Redactor
+	if !cfg.Redaction.Disabled {
+		var err error
+		if redactor, err = redact.New(cfg.Redaction.Patterns...); err != nil {
+			return nil, err
+		}
+		// Credentials of the provider are masked even without a pattern
+		// matching them
+		if provider, ok := gpt.Lookup(cfg.Provider); ok {
+			if settings, err := cfg.ResolvedSettings(cfg.Provider); err == nil {
+				for key, value := range settings {
+					if field, ok := provider.Field(key); ok && field.Secret {
+						redactor.AddValue(value)
+					}
+				}
+			}
+		}
+	}
+
+	var attachments []gpt.Attachment
+	for _, path := range cfg.Attachments {
+		attachment, err := gpt.LoadAttachment(path)
+		if err != nil {
+			return nil, err
+		}
+		if provider, ok := gpt.Lookup(cfg.Provider); ok && attachment.IsImage() && !provider.Images {
+			return nil, fmt.Errorf("%s provider does not support image attachments, remove %s", cfg.Provider, attachment.Name)
+		}
+		if !attachment.IsImage() {
+			attachment.Data = []byte(redactor.Redact(string(attachment.Data)))
+		}
+		attachments = append(attachments, attachment)
+	}
+
+	history := NewHistory(10)
+	if cfg.ReadOnly() {
+		// Answers are based on what commands print
+		history.OutputLimit = 4000
+	}
+
 	return &Agent{
-		config:    &Config{cfg},
-		logger:    log,
-		gptClient: gptClient,
-		history:   NewHistory(10),
-		stepCount: 0,
-		startTime: time.Now(),
+		config:      &Config{cfg},
+		logger:      log,
+		gptClient:   gptClient,
+		attachments: attachments,
+		history:     history,
+		stepCount:   0,
+		startTime:   time.Now(),
+		redactor:    redactor,
 	}, nil
 }
 
 func createGPTClient(cfg *config.Config) (gpt.Client, error) {
-	switch cfg.Provider {
-	case "yandex":
-		return gpt.NewYandexClient(cfg.FolderID, cfg.IAMToken), nil
-	case "openai":
-		return gpt.NewOpenAIClient(cfg.OpenAIKey, cfg.OpenAIModel), nil
-	case "gemini":
-		return gpt.NewGeminiClient(cfg.GeminiKey, cfg.GeminiModel), nil
-	case "claude":
-		return gpt.NewClaudeClient(cfg.ClaudeKey, cfg.ClaudeModel), nil
-	case "deepseek":
-		return gpt.NewDeepSeekClient(cfg.DeepSeekKey, cfg.DeepSeekModel), nil
-	case "ollama":
-		return gpt.NewOllamaClient(cfg.OllamaURL, cfg.OllamaModel), nil
-	default:
-		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
+	settings, err := cfg.ResolvedSettings(cfg.Provider)
+	if err != nil {
+		return nil, err
 	}
-}
+	options := cfg.GenerationOptions(cfg.Provider)
 
-func (a *Agent) Run(task string) error {
-	a.logger.StartAgent(a.config.Provider, task, a.config.MaxCommands, a.config.DryRun)
+	client, err := gpt.New(cfg.Provider, settings, options)
+	if err != nil {
+		return nil, err
+	}
+	if !cfg.Cache.Enabled || cfg.NoCache {
+		return client, nil
+	}
 
-	systemMessage := `You are an AI assistant that helps execute tasks by running shell commands.
+	cache, err := cfg.Cache.Open()
+	if err != nil {
+		return nil, err
+	}
+	return gpt.NewCachedClient(client, cache, gpt.CacheScope(cfg.Provider, settings, options)), nil
+}
+
+// SetGate makes agent ask gate before running commands
+func (a *Agent) SetGate(gate Gate) {
+	a.gate = gate
+}
 
-Your response must be a valid JSON object with this exact structure:
-{
-  "thought": "your reasoning about what to do next",
-  "command": "the shell command to execute"
+func (a *Agent) Run(task string) error {
+	return a.RunContext(context.Background(), task)
 }
 
-Rules:
-1. Always respond with valid JSON only
-2. Use the "thought" field to explain your reasoning
-3. Use the "command" field for the exact shell command to run
-4. If the task is complete, use "command": "TASK_COMPLETE"
-5. Be careful with destructive operations
-6. Consider the current directory and file structure
+// RunContext runs task until it is completed or ctx is cancelled,
+// cancelling also kills the running command
+func (a *Agent) RunContext(ctx context.Context, task string) error {
+	task = a.redactor.Redact(task)
+	a.logger.RunStarted(a.config.Provider, a.config.ProviderSettings(a.config.Provider)["model"], task, a.config.MaxCommands, a.config.DryRun)
+	for _, attachment := range a.attachments {
+		a.logger.Info("Attached %s (%s)", attachment.Name, attachment.MIMEType)
+	}
 
-Important guidelines for creating files:
-- For multi-line files, use 'cat > filename << EOF' followed by the content and 'EOF' on a new line
-- Never use echo with \n or \\n for multi-line content as it creates malformed files
-- Ensure proper formatting and indentation for code files
+	systemMessage := taskPrompt
+	switch a.config.Mode {
+	case config.ModeAsk:
+		systemMessage = askPrompt
+	case config.ModeExplain:
+		systemMessage = askPrompt + explainPrompt
+	}
 
-Example of correct multi-line file creation:
-cat > multiline << EOF
-first line
-second line
-EOF`
+	if a.config.SystemPrompt != "" {
+		systemMessage += "\n\nAdditional instructions:\n" + a.redactor.Redact(a.config.SystemPrompt)
+	}
+	a.logger.SystemPrompt(systemMessage)
 
 	for a.stepCount < a.config.MaxCommands {
+		if ctx.Err() != nil {
+			a.finish(ErrAborted)
+			return ErrAborted
+		}
 		a.stepCount++
-		a.logger.StartStep(a.stepCount)
+		a.logger.StepStarted(a.stepCount)
 
 		// Build user message with context
 		userMessage := fmt.Sprintf("Task: %s\n\n%s\n\nWhat should I do next?", task, a.history.GetContext())
 
 		// Get response from GPT
-		response, err := a.gptClient.Complete(systemMessage, userMessage)
+		a.logger.Prompt(userMessage)
+		requestStart := time.Now()
+		response, err := a.gptClient.Complete(gpt.Request{
+			System:      systemMessage,
+			User:        userMessage,
+			Attachments: a.attachments,
+		})
+		duration := time.Since(requestStart)
 		if err != nil {
+			a.logger.ModelResponse(logger.ModelResponse{Duration: duration, Error: err.Error()})
 			a.logger.Error("Failed to get GPT response: %v", err)
 			continue
 		}
 
-		// Parse the response
-		thought, command, err := a.parseResponse(response)
+		// Cached responses cost nothing, so they are not counted
+		if response.Cached {
+			a.logger.Debug("Response served from cache")
+		} else {
+			a.usage.InputTokens += response.Usage.InputTokens
+			a.usage.OutputTokens += response.Usage.OutputTokens
+			a.usage.ReasoningTokens += response.Usage.ReasoningTokens
+			a.usage.TotalTokens += response.Usage.TotalTokens
+		}
+
+		if response.Usage.TotalTokens > 0 && !response.Cached {
+			a.logger.Debug("Tokens used: %d input, %d output, %d reasoning",
+				response.Usage.InputTokens, response.Usage.OutputTokens, response.Usage.ReasoningTokens)
+		}
+
+		// Parse the response. Secrets the model repeats are masked in the
+		// decoded fields, masking the raw text could break its JSON
+		// escapes. Commands keep placeholders until they run
+		reply, err := a.parseResponse(response.Text)
+		reply = a.redactReply(reply)
+		response.Text = a.redactor.Redact(response.Text)
+		response.Reasoning = a.redactor.Redact(response.Reasoning)
+		a.logger.ModelResponse(logger.ModelResponse{
+			Text:            response.Text,
+			Thought:         reply.Thought,
+			Command:         reply.Command,
+			Reasoning:       response.Reasoning,
+			InputTokens:     response.Usage.InputTokens,
+			OutputTokens:    response.Usage.OutputTokens,
+			ReasoningTokens: response.Usage.ReasoningTokens,
+			Duration:        duration,
+			Cached:          response.Cached,
+		})
 		if err != nil {
 			a.logger.Error("Failed to parse response: %v", err)
-			a.logger.Debug("Raw response: %s", response)
+			a.logger.Debug("Raw response: %s", response.Text)
 			continue
 		}
 
 		// Check if task is complete
-		if command == "TASK_COMPLETE" {
-			a.logger.TaskCompleted(thought)
+		if reply.Answer != "" || reply.Command == "TASK_COMPLETE" {
+			a.complete(reply)
+			a.finish(nil)
 			return nil
 		}
 
 		// Execute the command
-		a.executeCommand(thought, command)
+		if err := a.executeCommand(ctx, reply.Thought, reply.Command, response.Reasoning); err != nil {
+			a.finish(err)
+			return err
+		}
+	}
+
+	err := fmt.Errorf("reached maximum number of commands (%d)", a.config.MaxCommands)
+	a.finish(err)
+	return err
+}
+
+// complete keeps result and answer of the final reply, summary falls
+// back to the thought when the model did not report a result
+func (a *Agent) complete(r reply) {
+	if r.Result != nil {
+		a.result = *r.Result
+	}
+	if a.result.Summary == "" {
+		a.result.Summary = r.Thought
 	}
+	if string(a.result.Data) == "null" {
+		a.result.Data = nil
+	}
+	a.answer = r.Answer
+}
+
+// finish reports totals of the run, err is nil for completed tasks
+func (a *Agent) finish(err error) {
+	if n := a.redactor.Count(); n > 0 {
+		a.logger.Debug("Masked %d secrets, placeholders were restored only in executed commands", n)
+	}
+	stats := a.Stats()
+	a.logger.RunFinished(logger.RunSummary{
+		Completed:    err == nil,
+		Summary:      a.result.Summary,
+		Answer:       a.answer,
+		FilesChanged: a.result.FilesChanged,
+		FollowUps:    a.result.FollowUps,
+		OutputData:   a.result.Data,
+		Error:        errorString(err),
+		Steps:        stats.Steps,
+		InputTokens:  stats.Usage.InputTokens,
+		OutputTokens: stats.Usage.OutputTokens,
+		TotalTokens:  stats.Usage.TotalTokens,
+		Duration:     stats.Duration,
+	})
+}
+
+// Stats returns steps, tokens and time spent so far
+func (a *Agent) Stats() Stats {
+	return Stats{
+		Steps:    a.stepCount,
+		Usage:    a.usage,
+		Duration: time.Since(a.startTime),
+	}
+}
+
+// Steps returns all executed steps, unlike history it is never truncated
+func (a *Agent) Steps() []Step {
+	return a.steps
+}
+
+// Redact masks secrets in text the same way the run did, used for
+// records kept outside of the agent
+func (a *Agent) Redact(text string) string {
+	return a.redactor.Redact(text)
+}
+
+// Summary returns summary of a completed task
+func (a *Agent) Summary() string {
+	return a.result.Summary
+}
+
+// Result returns outcome of a completed task
+func (a *Agent) Result() Result {
+	return a.result
+}
+
+// Answer returns final answer of a completed read-only run
+func (a *Agent) Answer() string {
+	return a.answer
+}
 
-	return fmt.Errorf("reached maximum number of commands (%d)", a.config.MaxCommands)
+// reply is a parsed model response, it has either a command or, in
+// read-only runs, the final answer. Result comes with TASK_COMPLETE
+type reply struct {
+	Thought string  `json:"thought"`
+	Command string  `json:"command"`
+	Answer  string  `json:"answer"`
+	Result  *Result `json:"result"`
 }
 
-func (a *Agent) parseResponse(response string) (string, string, error) {
+func (a *Agent) parseResponse(response string) (reply, error) {
 	// Try to extract JSON from the response
 	jsonStr := a.extractJSON(response)
 	if jsonStr == "" {
-		return "", "", fmt.Errorf("no JSON found in response")
+		return reply{}, fmt.Errorf("no JSON found in response")
 	}
 
 	return a.parseJSON(jsonStr)
@@ -195,74 +469,205 @@ func (a *Agent) extractJSON(response string) string {
 		return ""
 	}
 
-	// Find the matching closing brace
-	braceCount := 0
-	for i := start; i < len(response); i++ {
-		if response[i] == '{' {
-			braceCount++
-		} else if response[i] == '}' {
-			braceCount--
-			if braceCount == 0 {
-				return response[start : i+1]
-			}
-		}
+	// The first JSON value from there is the object, braces inside its
+	// strings don't end it early
+	var object json.RawMessage
+	if err := json.NewDecoder(strings.NewReader(response[start:])).Decode(&object); err != nil {
+		return ""
 	}
-
-	return ""
+	return string(object)
 }
 
-func (a *Agent) parseJSON(jsonStr string) (string, string, error) {
-	var parsed struct {
-		Thought string `json:"thought"`
-		Command string `json:"command"`
+func (a *Agent) parseJSON(jsonStr string) (reply, error) {
+	var parsed reply
+	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
+		a.logger.Debug("Failed to parse JSON: %s", a.redactor.Redact(jsonStr))
+		return reply{}, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
-	if err := json.Unmarshal([]byte(jsonStr), &parsed); err != nil {
-		a.logger.Debug("Failed to parse JSON: %s", jsonStr)
-		return "", "", fmt.Errorf("failed to parse JSON: %w", err)
+	// Only read-only runs finish with an answer
+	if !a.config.ReadOnly() {
+		parsed.Answer = ""
+	}
+	if parsed.Thought == "" || parsed.Command == "" && parsed.Answer == "" {
+		return reply{}, fmt.Errorf("missing required fields in JSON response")
+	}
+
+	return parsed, nil
+}
+
+// redactReply masks secrets in fields of parsed reply
+func (a *Agent) redactReply(r reply) reply {
+	r.Thought = a.redactor.Redact(r.Thought)
+	r.Command = a.redactor.Redact(r.Command)
+	r.Answer = a.redactor.Redact(r.Answer)
+	if r.Result == nil {
+		return r
+	}
+	result := *r.Result
+	result.Summary = a.redactor.Redact(result.Summary)
+	result.FilesChanged = a.redactList(result.FilesChanged)
+	result.FollowUps = a.redactList(result.FollowUps)
+	if len(result.Data) > 0 {
+		var data interface{}
+		decoder := json.NewDecoder(bytes.NewReader(result.Data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&data); err == nil {
+			if masked, err := json.Marshal(a.redactValue(data)); err == nil {
+				result.Data = masked
+			}
+		}
 	}
+	r.Result = &result
+	return r
+}
 
-	if parsed.Thought == "" || parsed.Command == "" {
-		return "", "", fmt.Errorf("missing required fields in JSON response")
+func (a *Agent) redactList(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	masked := make([]string, len(list))
+	for i, item := range list {
+		masked[i] = a.redactor.Redact(item)
 	}
+	return masked
+}
 
-	return parsed.Thought, parsed.Command, nil
+// redactValue masks secrets in strings of decoded JSON value
+func (a *Agent) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return a.redactor.Redact(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = a.redactValue(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = a.redactValue(item)
+		}
+	}
+	return value
 }
 
-func (a *Agent) executeCommand(thought, command string) {
+// executeCommand runs command and records it as a step, it fails only
+// when the run has to stop
+func (a *Agent) executeCommand(ctx context.Context, thought, command, reasoning string) error {
 	step := Step{
 		Number:    a.stepCount,
 		Timestamp: time.Now(),
 		Thought:   thought,
+		Reasoning: reasoning,
 		Command:   command,
 	}
 
-	a.logger.ExecuteCommand(command, thought)
+	// Command runs with real values of placeholders, everything recorded
+	// keeps them masked
+	restored := a.redactor.Restore(command)
+	assessment := risk.Classify(restored, a.config.WorkDir)
+	for i, reason := range assessment.Reasons {
+		assessment.Reasons[i] = a.redactor.Redact(reason)
+	}
+	step.Risk = assessment.Level.String()
+	a.logger.CommandStarted(command, thought, step.Risk, assessment.Reasons)
+
+	err := a.config.Policy.Check(restored)
+	if err == nil {
+		err = a.config.Policy.CheckRisk(assessment)
+	}
+	if err == nil && a.config.ReadOnly() {
+		if readErr := risk.ReadOnly(restored); readErr != nil {
+			err = fmt.Errorf("only read-only commands are allowed, %s", a.redactor.Redact(readErr.Error()))
+		}
+	}
+	if err != nil {
+		a.logger.Warning("Command refused by policy: %v", err)
+		step.Error = "refused by policy: " + err.Error()
+		step.Success = false
+		a.logger.CommandFinished("", -1, 0, logger.DecisionDenied, errors.New(step.Error))
+		a.addStep(step)
+		return nil
+	}
+
+	approval := !a.config.DryRun && a.config.Policy.NeedsApproval(assessment)
+	if approval && a.gate == nil {
+		a.logger.Warning("Command needs approval, but nobody can approve it in this run")
+		step.Error = fmt.Sprintf("refused by policy: %s risk commands need approval", assessment.Level)
+		a.logger.CommandFinished("", -1, 0, logger.DecisionDenied, errors.New(step.Error))
+		a.addStep(step)
+		return nil
+	}
+
+	decision := logger.DecisionAuto
+	if a.gate != nil {
+		switch a.gate(Proposal{Command: command, Thought: thought, Risk: assessment, Approval: approval}) {
+		case DecisionApprove:
+			decision = logger.DecisionApproved
+		case DecisionSkip:
+			a.logger.Warning("Command skipped by user")
+			step.Error = "skipped by user"
+			a.logger.CommandFinished("", -1, 0, logger.DecisionSkipped, errors.New(step.Error))
+			a.addStep(step)
+			return nil
+		case DecisionAbort:
+			a.logger.CommandFinished("", -1, 0, logger.DecisionAborted, ErrAborted)
+			return ErrAborted
+		}
+	}
 
 	if a.config.DryRun {
 		a.logger.Info("Dry run mode - command not executed")
 		step.Output = "DRY RUN - command not executed"
 		step.Success = true
-		a.history.AddStep(step)
-		return
+		a.logger.CommandFinished(step.Output, 0, 0, logger.DecisionDryRun, nil)
+		a.addStep(step)
+		return nil
 	}
 
 	// Execute the command
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout, _ := a.config.Timeout()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", command)
-	output, err := cmd.CombinedOutput()
-
-	step.Output = string(output)
+	cmd := exec.CommandContext(ctx, "bash", "-c", restored)
+	cmd.Dir = a.config.WorkDir
+	// Output is collected and streamed at the same time, a single writer
+	// for both streams keeps their order like CombinedOutput does
+	var buffer bytes.Buffer
+	stream := a.redactor.Writer(a.logger.CommandOutput())
+	writer := io.MultiWriter(&buffer, stream)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+	commandStart := time.Now()
+	err = cmd.Run()
+	stream.Flush()
+	output := a.redactor.Redact(buffer.String())
+	step.Duration = time.Since(commandStart)
+	a.logger.CommandFinished(output, cmd.ProcessState.ExitCode(), step.Duration, decision, err)
+
+	step.Output = output
 	if err != nil {
-		step.Error = err.Error()
+		step.Error = a.redactor.Redact(err.Error())
 		step.Success = false
-		a.logger.CommandError(err)
 	} else {
 		step.Success = true
-		a.logger.CommandSuccess(string(output))
 	}
 
+	a.addStep(step)
+	if ctx.Err() == context.Canceled {
+		return ErrAborted
+	}
+	return nil
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func (a *Agent) addStep(step Step) {
 	a.history.AddStep(step)
+	a.steps = append(a.steps, step)
 }
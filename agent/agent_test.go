@@ -0,0 +1,401 @@
+package agent
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+// newTestAgent creates agent replaying responses in a fresh working directory
+func newTestAgent(t *testing.T, cfg *config.Config, responses ...gpt.MockResponse) (*Agent, *gpt.MockClient) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	cfg.Provider = "mock"
+	if cfg.MaxCommands == 0 {
+		cfg.MaxCommands = 5
+	}
+
+	client := gpt.NewMockClient(responses...)
+	a, err := NewWithClient(cfg, logger.New(false, true), client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a, client
+}
+
+func TestRunCompletesTask(t *testing.T) {
+	a, client := newTestAgent(t, nil,
+		gpt.MockResponse{Thought: "create file", Command: "echo hello > out.txt"},
+		gpt.MockResponse{Thought: "check file", Command: "cat out.txt"},
+		gpt.MockResponse{Thought: "file exists", Command: "TASK_COMPLETE"},
+	)
+
+	if err := a.Run("write hello to out.txt"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile("out.txt")
+	if err != nil || string(data) != "hello\n" {
+		t.Fatalf("command was not executed, out.txt = %q, %v", data, err)
+	}
+
+	steps := a.history.Steps
+	if len(steps) != 2 || steps[1].Output != "hello\n" || !steps[1].Success {
+		t.Fatalf("unexpected history %+v", steps)
+	}
+
+	// Later prompts carry history of previous steps
+	requests := client.Requests()
+	if !strings.Contains(requests[2].User, "Command: cat out.txt") {
+		t.Fatalf("history not sent to model:\n%s", requests[2].User)
+	}
+}
+
+func TestRunParsesJSONWrappedInText(t *testing.T) {
+	a, _ := newTestAgent(t, nil,
+		gpt.MockResponse{Text: "Sure! Here is the next step:\n```json\n{\"thought\": \"list\", \"command\": \"ls\"}\n```"},
+		gpt.MockResponse{Text: "Done.\n" + `{"thought": "done", "command": "TASK_COMPLETE", "result": {"summary": "added the missing } to main.go"}} {`},
+	)
+
+	if err := a.Run("list files"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(a.history.Steps) != 1 || a.history.Steps[0].Command != "ls" {
+		t.Fatalf("unexpected history %+v", a.history.Steps)
+	}
+	if a.Summary() != "added the missing } to main.go" {
+		t.Fatalf("unexpected summary %q", a.Summary())
+	}
+}
+
+func TestRunDryRun(t *testing.T) {
+	a, _ := newTestAgent(t, &config.Config{DryRun: true},
+		gpt.MockResponse{Thought: "create file", Command: "touch created"},
+		gpt.MockResponse{Thought: "done", Command: "TASK_COMPLETE"},
+	)
+
+	if err := a.Run("create file"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat("created"); !os.IsNotExist(err) {
+		t.Fatalf("dry run executed the command")
+	}
+	if a.history.Steps[0].Output != "DRY RUN - command not executed" {
+		t.Fatalf("unexpected output %q", a.history.Steps[0].Output)
+	}
+}
+
+func TestRunMaxCommands(t *testing.T) {
+	a, client := newTestAgent(t, &config.Config{MaxCommands: 2},
+		gpt.MockResponse{Thought: "one", Command: "true"},
+		gpt.MockResponse{Thought: "two", Command: "true"},
+		gpt.MockResponse{Thought: "three", Command: "true"},
+	)
+
+	err := a.Run("loop forever")
+	if err == nil || !strings.Contains(err.Error(), "reached maximum number of commands (2)") {
+		t.Fatalf("expected max commands error, got %v", err)
+	}
+	if len(client.Requests()) != 2 {
+		t.Fatalf("expected 2 model calls, got %d", len(client.Requests()))
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	a, _ := newTestAgent(t, nil,
+		gpt.MockResponse{Error: "rate limited"},
+		gpt.MockResponse{Text: "I am not sure what to do"},
+		gpt.MockResponse{Text: `{"thought": "missing command"}`},
+		gpt.MockResponse{Thought: "fail", Command: "exit 3"},
+		gpt.MockResponse{Thought: "give up", Command: "TASK_COMPLETE"},
+	)
+
+	if err := a.Run("fail a lot"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Only the failed command makes it into history
+	steps := a.history.Steps
+	if len(steps) != 1 {
+		t.Fatalf("unexpected history %+v", steps)
+	}
+	if steps[0].Success || !strings.Contains(steps[0].Error, "exit status 3") {
+		t.Fatalf("failed command recorded as %+v", steps[0])
+	}
+	if a.stepCount != 5 {
+		t.Fatalf("every model call must consume a step, got %d", a.stepCount)
+	}
+}
+
+func TestRunWithMockProvider(t *testing.T) {
+	script := t.TempDir() + "/script.yml"
+	err := os.WriteFile(script, []byte(`responses:
+  - thought: say hi
+    command: echo hi
+  - thought: done
+    command: TASK_COMPLETE
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{
+		Provider:    "mock",
+		MaxCommands: 3,
+		Providers: map[string]*config.ProviderConfig{
+			"mock": {Settings: gpt.Settings{"script": script}},
+		},
+	}
+	a, err := New(cfg, logger.New(false, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Run("say hi"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if a.history.Steps[0].Output != "hi\n" {
+		t.Fatalf("unexpected output %q", a.history.Steps[0].Output)
+	}
+}
+
+func TestRunAppliesPolicyAndSystemPrompt(t *testing.T) {
+	a, client := newTestAgent(t, &config.Config{
+		SystemPrompt: "Use make for builds.",
+		Policy:       config.Policy{Deny: []string{`^rm\b`}},
+	},
+		gpt.MockResponse{Thought: "clean up", Command: "rm -f keep.txt"},
+		gpt.MockResponse{Thought: "done", Command: "TASK_COMPLETE"},
+	)
+	if err := os.WriteFile("keep.txt", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Run("clean up"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat("keep.txt"); err != nil {
+		t.Fatal("denied command was executed")
+	}
+
+	steps := a.Steps()
+	if len(steps) != 1 || steps[0].Success || !strings.Contains(steps[0].Error, "refused by policy") {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+	if !strings.HasSuffix(client.Requests()[0].System, "Additional instructions:\nUse make for builds.") {
+		t.Fatalf("system prompt addition not sent:\n%s", client.Requests()[0].System)
+	}
+}
+
+func TestRunGate(t *testing.T) {
+	a, _ := newTestAgent(t, nil,
+		gpt.MockResponse{Thought: "skip me", Command: "touch skipped"},
+		gpt.MockResponse{Thought: "run me", Command: "touch executed"},
+		gpt.MockResponse{Thought: "stop here", Command: "touch aborted"},
+		gpt.MockResponse{Thought: "done", Command: "TASK_COMPLETE"},
+	)
+	decisions := []Decision{DecisionSkip, DecisionRun, DecisionAbort}
+	a.SetGate(func(p Proposal) Decision {
+		decision := decisions[0]
+		decisions = decisions[1:]
+		return decision
+	})
+
+	if err := a.Run("touch files"); err != ErrAborted {
+		t.Fatalf("expected abort, got %v", err)
+	}
+	for name, exists := range map[string]bool{"skipped": false, "executed": true, "aborted": false} {
+		if _, err := os.Stat(name); (err == nil) != exists {
+			t.Errorf("file %s exists: %t, want %t", name, err == nil, exists)
+		}
+	}
+
+	steps := a.Steps()
+	if len(steps) != 2 || steps[0].Error != "skipped by user" || !steps[1].Success {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+}
+
+func TestRunRiskPolicy(t *testing.T) {
+	a, _ := newTestAgent(t, &config.Config{
+		Policy: config.Policy{MaxRisk: "medium", ApproveRisk: "medium"},
+	},
+		gpt.MockResponse{Thought: "open up", Command: "chmod 777 keep.txt"},
+		gpt.MockResponse{Thought: "clean up", Command: "rm -f keep.txt"},
+		gpt.MockResponse{Thought: "mark", Command: "touch done.txt"},
+		gpt.MockResponse{Thought: "done", Command: "TASK_COMPLETE"},
+	)
+	if err := os.WriteFile("keep.txt", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	var asked []string
+	a.SetGate(func(p Proposal) Decision {
+		if p.Approval {
+			asked = append(asked, p.Command)
+			return DecisionApprove
+		}
+		return DecisionRun
+	})
+
+	if err := a.Run("clean up"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(asked) != 1 || asked[0] != "rm -f keep.txt" {
+		t.Fatalf("approval asked for %v", asked)
+	}
+
+	steps := a.Steps()
+	if len(steps) != 3 || !strings.Contains(steps[0].Error, "high risk") || steps[0].Risk != "high" {
+		t.Fatalf("high risk command not denied: %+v", steps)
+	}
+	if !steps[1].Success || steps[1].Risk != "medium" || !steps[2].Success || steps[2].Risk != "low" {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+}
+
+func TestRunAsk(t *testing.T) {
+	a, client := newTestAgent(t, &config.Config{Mode: config.ModeAsk},
+		gpt.MockResponse{Thought: "tidy up first", Command: "rm -f port.txt"},
+		gpt.MockResponse{Thought: "read config", Command: "cat port.txt"},
+		gpt.MockResponse{Thought: "config has the port", Command: "TASK_COMPLETE", Answer: "The server listens on **8080**."},
+	)
+	if err := os.WriteFile("port.txt", []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := a.Run("which port does the server use?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := os.Stat("port.txt"); err != nil {
+		t.Fatal("command changing files was executed")
+	}
+	if a.Answer() != "The server listens on **8080**." || a.Summary() != "config has the port" {
+		t.Fatalf("unexpected answer %q and summary %q", a.Answer(), a.Summary())
+	}
+
+	steps := a.Steps()
+	if len(steps) != 2 || !strings.Contains(steps[0].Error, "rm is not a read-only command") || !steps[1].Success {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+	requests := client.Requests()
+	if !strings.Contains(requests[0].System, `"answer"`) || !strings.Contains(requests[2].User, "Output:\nport: 8080\n") {
+		t.Fatalf("answer protocol or outputs missing from requests:\n%s\n%s", requests[0].System, requests[2].User)
+	}
+}
+
+func TestRunResult(t *testing.T) {
+	a, _ := newTestAgent(t, nil,
+		gpt.MockResponse{Thought: "create file", Command: "echo 1 > count.txt"},
+		gpt.MockResponse{Thought: "file is there", Command: "TASK_COMPLETE", Result: map[string]interface{}{
+			"summary":       "Created count.txt",
+			"files_changed": []string{"count.txt"},
+			"follow_ups":    []string{"commit it"},
+			"data":          map[string]int{"count": 1},
+		}},
+	)
+	memory := logger.NewMemory(logger.LevelNotice)
+	a.logger = logger.NewWithSinks(memory)
+
+	if err := a.Run("count to one"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := a.Result()
+	if result.Summary != "Created count.txt" || len(result.FilesChanged) != 1 || result.FollowUps[0] != "commit it" || string(result.Data) != `{"count":1}` {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	events := memory.Events()
+	if last := events[len(events)-1]; last.Summary != result.Summary || string(last.OutputData) != `{"count":1}` {
+		t.Fatalf("result not logged: %+v", last)
+	}
+
+	// Without a result the thought is the summary
+	a, _ = newTestAgent(t, nil, gpt.MockResponse{Text: `{"thought": "nothing to do", "command": "TASK_COMPLETE", "result": {"data": null}}`})
+	if err := a.Run("do nothing"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result := a.Result(); result.Summary != "nothing to do" || result.Data != nil {
+		t.Fatalf("unexpected result %+v", result)
+	}
+}
+
+func TestRunRedactsSecrets(t *testing.T) {
+	a, client := newTestAgent(t, nil,
+		gpt.MockResponse{Thought: "read config", Command: "echo DB_PASSWORD=hunter2secret"},
+		gpt.MockResponse{Thought: "use it", Command: "printf %s [SECRET_1] > used.txt"},
+		gpt.MockResponse{Thought: "show it", Command: "echo [SECRET_1]"},
+		gpt.MockResponse{Thought: "done", Command: "TASK_COMPLETE"},
+	)
+
+	if err := a.Run("connect with token=abcd1234efgh"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, request := range client.Requests() {
+		if strings.Contains(request.User, "abcd1234efgh") {
+			t.Fatalf("secret of the task sent to model:\n%s", request.User)
+		}
+	}
+	steps := a.Steps()
+	if steps[0].Output != "DB_PASSWORD=[SECRET_2]\n" {
+		t.Fatalf("output not redacted: %q", steps[0].Output)
+	}
+
+	// Placeholders in commands are replaced with real values when they run
+	data, err := os.ReadFile("used.txt")
+	if err != nil || string(data) != "abcd1234efgh" {
+		t.Fatalf("placeholder not restored, used.txt = %q, %v", data, err)
+	}
+	if steps[1].Command != "printf %s [SECRET_1] > used.txt" {
+		t.Fatalf("recorded command reveals secret: %q", steps[1].Command)
+	}
+	// Output of a restored command masks the secret again
+	if steps[2].Output != "[SECRET_1]\n" {
+		t.Fatalf("output of restored command reveals secret: %q", steps[2].Output)
+	}
+}
+
+func TestRunRedactsQuotedSecrets(t *testing.T) {
+	a, _ := newTestAgent(t, nil,
+		gpt.MockResponse{Thought: "write config", Command: `echo "token: abcdef12" > cfg`},
+		gpt.MockResponse{Thought: "done", Command: "TASK_COMPLETE", Result: map[string]interface{}{
+			"summary": "wrote token: abcdef12",
+			"data":    map[string]interface{}{"line": "token: abcdef12"},
+		}},
+	)
+
+	if err := a.Run("store the token"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	steps := a.Steps()
+	if len(steps) != 1 || steps[0].Command != `echo "token: [SECRET_1]" > cfg` {
+		t.Fatalf("unexpected steps %+v", steps)
+	}
+	data, err := os.ReadFile("cfg")
+	if err != nil || string(data) != "token: abcdef12\n" {
+		t.Fatalf("placeholder not restored, cfg = %q, %v", data, err)
+	}
+	result := a.Result()
+	if result.Summary != "wrote token: [SECRET_1]" || string(result.Data) != `{"line":"token: [SECRET_1]"}` {
+		t.Fatalf("result not redacted: %+v %s", result, result.Data)
+	}
+}
+
+func TestHistoryOutputLimitKeepsCharactersWhole(t *testing.T) {
+	h := NewHistory(5)
+	h.OutputLimit = 4
+	h.AddStep(Step{Number: 1, Thought: "t", Command: "cat", Output: "aé€", Success: true})
+
+	context := h.GetContext()
+	if !utf8.ValidString(context) || !strings.Contains(context, "Output:\n...€\n") {
+		t.Fatalf("unexpected context:\n%q", context)
+	}
+}
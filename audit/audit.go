@@ -0,0 +1,270 @@
+// Package audit keeps an append-only log of commands proposed by the
+// agent. Every record carries hash of the previous one, so editing,
+// removing or inserting records breaks the chain and is found by Verify
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d1nch8g/g8t/logger"
+)
+
+// genesis is previous hash of the first record
+var genesis = strings.Repeat("0", 64)
+
+// Record describes a single command and what happened to it. Command and
+// output are redacted like everything else leaving the agent, output is
+// kept only as its hash
+type Record struct {
+	Seq      int    `json:"seq"`
+	Time     string `json:"time"`
+	User     string `json:"user"`
+	Host     string `json:"host"`
+	Cwd      string `json:"cwd"`
+	Session  string `json:"session"`
+	Provider string `json:"provider"`
+	Model    string `json:"model,omitempty"`
+	Command  string `json:"command"`
+	Risk     string `json:"risk,omitempty"`
+	// Decision is one of logger.Decision* values
+	Decision   string `json:"decision"`
+	ExitCode   *int   `json:"exit_code,omitempty"`
+	OutputHash string `json:"output_sha256,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Prev       string `json:"prev"`
+	Hash       string `json:"hash,omitempty"`
+}
+
+// Log appends records of a run to the audit file. It is a logger sink
+// turning finished commands into records
+type Log struct {
+	path string
+	// base holds fields shared by all records of the run
+	base Record
+
+	mu      sync.Mutex
+	command string
+	risk    string
+	err     error
+}
+
+// Open checks that audit file at path can be written and creates log
+// for records of session running in workDir
+func Open(path, session, workDir string) (*Log, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	f.Close()
+
+	if workDir == "" {
+		workDir, _ = os.Getwd()
+	}
+	host, _ := os.Hostname()
+	return &Log{
+		path: path,
+		base: Record{User: currentUser(), Host: host, Cwd: workDir, Session: session},
+	}, nil
+}
+
+func (l *Log) Level() logger.Level {
+	return logger.LevelNotice
+}
+
+func (l *Log) Write(e logger.Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	switch e.Type {
+	case logger.EventRunStarted:
+		l.base.Provider = e.Provider
+		l.base.Model = e.Model
+	case logger.EventCommandStarted:
+		l.command = e.Command
+		l.risk = e.Risk
+	case logger.EventCommandFinished:
+		record := l.base
+		record.Time = e.Time.UTC().Format(time.RFC3339Nano)
+		record.Command = l.command
+		record.Risk = l.risk
+		record.Decision = e.Decision
+		record.Error = e.Error
+		if e.Decision == logger.DecisionAuto || e.Decision == logger.DecisionApproved {
+			record.ExitCode = e.ExitCode
+			sum := sha256.Sum256([]byte(e.Output))
+			record.OutputHash = hex.EncodeToString(sum[:])
+		}
+		if err := l.append(record); err != nil && l.err == nil {
+			l.err = err
+		}
+	}
+}
+
+// Err returns the first error writing records, a run with incomplete
+// audit trail should not pass unnoticed
+func (l *Log) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
+
+// append chains record to the last one in the file, the file is locked
+// so concurrent runs keep the chain intact
+func (l *Log) append(record Record) error {
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+	if err := lock(f); err != nil {
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer unlock(f)
+
+	last, err := lastLine(f)
+	if err != nil {
+		return fmt.Errorf("failed to read audit log: %w", err)
+	}
+	record.Prev = genesis
+	if last != "" {
+		var prev Record
+		if err := json.Unmarshal([]byte(last), &prev); err != nil {
+			return fmt.Errorf("failed to parse last audit record: %w", err)
+		}
+		record.Seq = prev.Seq + 1
+		record.Prev = prev.Hash
+	}
+	record.Hash = record.hash()
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit record: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// hash is SHA-256 of the record encoded without its own hash
+func (r Record) hash() string {
+	r.Hash = ""
+	data, _ := json.Marshal(r)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Result summarizes a verified audit log
+type Result struct {
+	Records int
+	// Last is hash of the last record, keeping it elsewhere also
+	// detects removal of records at the end
+	Last string
+}
+
+// Verify checks every record of audit log at path and returns an error
+// naming the first line that was tampered with
+func Verify(path string) (Result, error) {
+	result := Result{Last: genesis}
+	path, err := expandHome(path)
+	if err != nil {
+		return result, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return result, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return result, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+		switch {
+		case record.Seq != result.Records:
+			return result, fmt.Errorf("line %d: record %d found where %d was expected", line, record.Seq, result.Records)
+		case record.Prev != result.Last:
+			return result, fmt.Errorf("line %d: record does not follow the previous one", line)
+		case record.Hash != record.hash():
+			return result, fmt.Errorf("line %d: record was modified", line)
+		}
+		result.Records++
+		result.Last = record.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return result, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return result, nil
+}
+
+// lastLine returns the last non-empty line of f reading it from the end,
+// records may be long but the log may be much longer
+func lastLine(f *os.File) (string, error) {
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return "", err
+	}
+
+	var tail []byte
+	chunk := make([]byte, 4096)
+	for offset := size; offset > 0; {
+		n := int64(len(chunk))
+		if offset < n {
+			n = offset
+		}
+		offset -= n
+		if _, err := f.ReadAt(chunk[:n], offset); err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		tail = append(append([]byte{}, chunk[:n]...), tail...)
+
+		trimmed := strings.TrimRight(string(tail), "\n")
+		if i := strings.LastIndexByte(trimmed, '\n'); i >= 0 {
+			return trimmed[i+1:], nil
+		}
+		if offset == 0 {
+			return trimmed, nil
+		}
+	}
+	return "", nil
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, path[2:]), nil
+}
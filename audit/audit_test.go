@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d1nch8g/g8t/logger"
+)
+
+// writeRun records a run with an executed, a denied and a skipped command
+func writeRun(t *testing.T, path, session string) *Log {
+	t.Helper()
+	log, err := Open(path, session, "/srv/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := logger.NewWithSinks(log)
+	run.RunStarted("openai", "gpt-4o", "deploy", 5, false)
+	run.CommandStarted("ls", "look", "low", nil)
+	run.CommandFinished("main.go\n", 0, time.Millisecond, logger.DecisionAuto, nil)
+	run.CommandStarted("rm -rf /", "clean", "critical", []string{"deletes /"})
+	run.CommandFinished("", -1, 0, logger.DecisionDenied, errors.New("refused by policy"))
+	run.CommandStarted("reboot", "restart", "low", nil)
+	run.CommandFinished("", -1, 0, logger.DecisionSkipped, errors.New("skipped by user"))
+	return log
+}
+
+func TestLogAndVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	if log := writeRun(t, path, "first"); log.Err() != nil {
+		t.Fatal(log.Err())
+	}
+	writeRun(t, path, "second")
+
+	result, err := Verify(path)
+	if err != nil || result.Records != 6 {
+		t.Fatalf("got %d records, %v", result.Records, err)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	var first, denied, next, last Record
+	json.Unmarshal([]byte(lines[0]), &first)
+	json.Unmarshal([]byte(lines[1]), &denied)
+	json.Unmarshal([]byte(lines[3]), &next)
+	json.Unmarshal([]byte(lines[5]), &last)
+
+	if first.Prev != genesis || first.Command != "ls" || first.Decision != logger.DecisionAuto ||
+		first.ExitCode == nil || *first.ExitCode != 0 || first.Provider != "openai" || first.Cwd != "/srv/app" {
+		t.Errorf("unexpected first record %+v", first)
+	}
+	if sum := sha256.Sum256([]byte("main.go\n")); first.OutputHash != hex.EncodeToString(sum[:]) {
+		t.Errorf("unexpected output hash %s", first.OutputHash)
+	}
+	if denied.Decision != logger.DecisionDenied || denied.Risk != "critical" || denied.ExitCode != nil || denied.OutputHash != "" {
+		t.Errorf("unexpected denied record %+v", denied)
+	}
+	if next.Session != "second" || next.Seq != 3 || next.Prev == "" {
+		t.Errorf("second run does not continue the chain %+v", next)
+	}
+	if result.Last != last.Hash {
+		t.Error("last hash does not match the last record")
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	cases := map[string]func(lines []string) []string{
+		"modified": func(lines []string) []string {
+			lines[1] = strings.Replace(lines[1], "rm -rf /", "rm -rf /tmp", 1)
+			return lines
+		},
+		"removed": func(lines []string) []string {
+			return append(lines[:1], lines[2:]...)
+		},
+		"reordered": func(lines []string) []string {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		},
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.jsonl")
+			writeRun(t, path, "run")
+			data, _ := os.ReadFile(path)
+			lines := tamper(strings.Split(strings.TrimSpace(string(data)), "\n"))
+			os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0600)
+
+			result, err := Verify(path)
+			if err == nil || !strings.HasPrefix(err.Error(), "line 2:") || result.Records != 1 {
+				t.Fatalf("tampering not detected at line 2: %d records, %v", result.Records, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package audit
+
+import "os"
+
+// Other platforms rely on the file being opened for appending
+func lock(f *os.File) error {
+	return nil
+}
+
+func unlock(f *os.File) error {
+	return nil
+}
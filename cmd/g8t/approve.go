@@ -0,0 +1,37 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/d1nch8g/g8t/agent"
+)
+
+// promptGate asks on the terminal before running commands policy wants
+// approved, other commands run without asking
+func promptGate(in io.Reader, out io.Writer) agent.Gate {
+	reader := bufio.NewReader(in)
+	return func(p agent.Proposal) agent.Decision {
+		if !p.Approval {
+			return agent.DecisionRun
+		}
+		for {
+			fmt.Fprintf(out, "   Run this %s risk command? [y]es, [n]o, [a]bort: ", p.Risk.Level)
+			answer, err := reader.ReadString('\n')
+			if err != nil {
+				fmt.Fprintln(out)
+				return agent.DecisionAbort
+			}
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "y", "yes":
+				return agent.DecisionApprove
+			case "n", "no":
+				return agent.DecisionSkip
+			case "a", "abort":
+				return agent.DecisionAbort
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/d1nch8g/g8t/audit"
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+func runAudit(args []string, log logger.Logger) error {
+	flags := newFlagSet("audit", "g8t audit verify [file]",
+		"Checks the hash chain of the audit log, configured by audit_log, and\n"+
+			"reports the first record that was modified, removed or inserted.")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 || len(positional) > 2 {
+		flags.printUsage()
+		return fmt.Errorf("audit command is required")
+	}
+	if positional[0] != "verify" {
+		flags.printUsage()
+		return fmt.Errorf("unknown audit command: %s", positional[0])
+	}
+
+	path := ""
+	if len(positional) == 2 {
+		path = positional[1]
+	} else {
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		if err := cfg.ApplyEnv(); err != nil {
+			return err
+		}
+		if cfg.AuditLog == "" {
+			return fmt.Errorf("audit log is not configured, set audit_log or pass a file")
+		}
+		path = cfg.AuditLog
+	}
+
+	result, err := audit.Verify(path)
+	if err != nil {
+		return fmt.Errorf("audit log verification failed after %d valid records: %w", result.Records, err)
+	}
+	log.Success("Audit log is intact: %d records, last hash %s", result.Records, result.Last)
+	return nil
+}
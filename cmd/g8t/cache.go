@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+func runCache(args []string, log logger.Logger) error {
+	flags := newFlagSet("cache", "g8t cache stats|clear",
+		"Shows size of the response cache or removes all cached responses.")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		flags.printUsage()
+		return fmt.Errorf("cache command is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cache, err := cfg.Cache.Open()
+	if err != nil {
+		return err
+	}
+
+	switch positional[0] {
+	case "stats":
+		stats, err := cache.Stats()
+		if err != nil {
+			return err
+		}
+		enabled := "disabled"
+		if cfg.Cache.Enabled {
+			enabled = "enabled"
+		}
+		fmt.Printf("Directory: %s (%s)\n", stats.Dir, enabled)
+		fmt.Printf("Entries:   %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size:      %.1f MB of %.0f MB\n", float64(stats.Size)/(1024*1024), float64(cache.MaxSize)/(1024*1024))
+		fmt.Printf("TTL:       %s\n", cache.TTL)
+		if stats.Entries > 0 {
+			fmt.Printf("Oldest:    %s\n", stats.Oldest.Format(time.RFC3339))
+			fmt.Printf("Newest:    %s\n", stats.Newest.Format(time.RFC3339))
+		}
+	case "clear":
+		if err := cache.Clear(); err != nil {
+			return err
+		}
+		log.Success("Cache cleared")
+	default:
+		flags.printUsage()
+		return fmt.Errorf("unknown cache command: %s", positional[0])
+	}
+	return nil
+}
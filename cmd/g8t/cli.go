@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// flagSet wraps flag.FlagSet with short aliases, flags interleaved with
+// positional arguments and help output shared by all commands
+type flagSet struct {
+	*flag.FlagSet
+	usage       string
+	description string
+	// aliases maps long flag names to their short forms
+	aliases map[string]string
+	// footer is printed after options, optional
+	footer string
+}
+
+func newFlagSet(name, usage, description string) *flagSet {
+	f := &flagSet{
+		FlagSet:     flag.NewFlagSet(name, flag.ContinueOnError),
+		usage:       usage,
+		description: description,
+		aliases:     map[string]string{},
+	}
+	f.SetOutput(os.Stderr)
+	f.FlagSet.Usage = f.printUsage
+	return f
+}
+
+// alias registers short name for an already defined flag
+func (f *flagSet) alias(short, long string) {
+	f.Var(f.Lookup(long).Value, short, "")
+	f.aliases[long] = short
+}
+
+// isSet reports whether flag was given by its long or short name
+func (f *flagSet) isSet(long string) bool {
+	set := false
+	f.Visit(func(fl *flag.Flag) {
+		if fl.Name == long || fl.Name == f.aliases[long] {
+			set = true
+		}
+	})
+	return set
+}
+
+// parse parses flags placed anywhere among positional arguments,
+// everything after "--" is positional
+func (f *flagSet) parse(args []string) ([]string, error) {
+	var positional []string
+	for len(args) > 0 {
+		if args[0] == "--" {
+			positional = append(positional, args[1:]...)
+			break
+		}
+		if !strings.HasPrefix(args[0], "-") || args[0] == "-" {
+			positional = append(positional, args[0])
+			args = args[1:]
+			continue
+		}
+
+		if err := f.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := f.Args()
+		// Parse stops after consuming "--", the rest is positional then
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			positional = append(positional, rest...)
+			break
+		}
+		args = rest
+	}
+	return positional, nil
+}
+
+func (f *flagSet) printUsage() {
+	out := f.Output()
+	fmt.Fprintf(out, "Usage: %s\n\n%s\n", f.usage, f.description)
+
+	short := map[string]bool{}
+	for _, s := range f.aliases {
+		short[s] = true
+	}
+
+	var lines [][2]string
+	f.VisitAll(func(fl *flag.Flag) {
+		if short[fl.Name] {
+			return
+		}
+		name := "--" + fl.Name
+		if s, ok := f.aliases[fl.Name]; ok {
+			name = "-" + s + ", " + name
+		}
+		arg, usage := flag.UnquoteUsage(fl)
+		if arg != "" {
+			name += " " + arg
+		}
+		if fl.DefValue != "" && fl.DefValue != "false" && fl.DefValue != "0" && fl.DefValue != "[]" {
+			usage += fmt.Sprintf(" (default %s)", fl.DefValue)
+		}
+		lines = append(lines, [2]string{name, usage})
+	})
+
+	if len(lines) > 0 {
+		width := 0
+		for _, line := range lines {
+			if len(line[0]) > width {
+				width = len(line[0])
+			}
+		}
+		fmt.Fprintf(out, "\nOptions:\n")
+		for _, line := range lines {
+			fmt.Fprintf(out, "  %-*s   %s\n", width, line[0], line[1])
+		}
+	}
+
+	if f.footer != "" {
+		fmt.Fprintf(out, "\n%s", f.footer)
+	}
+}
+
+// stringList collects values of a repeatable flag
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
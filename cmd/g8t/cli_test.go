@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFlagSetParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		positional []string
+		provider   string
+		verbose    bool
+		attach     []string
+	}{
+		{"flags first", []string{"-p", "claude", "fix", "build"}, []string{"fix", "build"}, "claude", false, nil},
+		{"flags after task", []string{"fix", "build", "-v", "--provider=gemini"}, []string{"fix", "build"}, "gemini", true, nil},
+		{"interleaved", []string{"fix", "-a", "x.png", "build", "--attach", "y.txt"}, []string{"fix", "build"}, "", false, []string{"x.png", "y.txt"}},
+		{"separator", []string{"-v", "--", "rm", "-rf", "-p"}, []string{"rm", "-rf", "-p"}, "", true, nil},
+		{"separator after task", []string{"clean", "--", "-v"}, []string{"clean", "-v"}, "", false, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := newFlagSet("test", "test", "test")
+			provider := flags.String("provider", "", "")
+			verbose := flags.Bool("verbose", false, "")
+			var attach stringList
+			flags.Var(&attach, "attach", "")
+			flags.alias("p", "provider")
+			flags.alias("v", "verbose")
+			flags.alias("a", "attach")
+
+			positional, err := flags.parse(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(positional, tt.positional) {
+				t.Errorf("positional = %q, want %q", positional, tt.positional)
+			}
+			if *provider != tt.provider || *verbose != tt.verbose {
+				t.Errorf("provider = %q, verbose = %t", *provider, *verbose)
+			}
+			if !reflect.DeepEqual([]string(attach), tt.attach) {
+				t.Errorf("attach = %q, want %q", attach, tt.attach)
+			}
+		})
+	}
+}
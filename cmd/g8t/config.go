@@ -0,0 +1,390 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+	"github.com/d1nch8g/g8t/secret"
+	"gopkg.in/yaml.v3"
+)
+
+func runSetup(args []string, log logger.Logger) error {
+	flags := newFlagSet("setup", "g8t setup [--non-interactive [--set key=value]...]",
+		"Asks for provider and general settings and saves them to ~/.g8t.yml.\n"+
+			"With --non-interactive nothing is asked, values come from G8T_* environment\n"+
+			"variables and --set assignments, e.g. for provisioning machines by scripts.")
+	nonInteractive := flags.Bool("non-interactive", false, "Take all values from environment and --set")
+	var assignments stringList
+	flags.Var(&assignments, "set", "Set dotted `key=value`, can be repeated")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) > 0 {
+		flags.printUsage()
+		return fmt.Errorf("setup takes no arguments")
+	}
+
+	if !*nonInteractive {
+		if len(assignments) > 0 {
+			return fmt.Errorf("--set requires --non-interactive")
+		}
+		_, err = config.Setup()
+		return err
+	}
+
+	if _, err := config.SetupNonInteractive(assignments); err != nil {
+		return err
+	}
+	path, _ := config.Path()
+	log.Success("Configuration saved to %s", path)
+	return nil
+}
+
+func runConfig(args []string, log logger.Logger) error {
+	flags := newFlagSet("config", "g8t config <command> [options]",
+		"Manages configuration without the setup wizard. Keys are dotted paths like\n"+
+			"max_commands, generation.temperature or providers.openai.model.")
+	resolved := flags.Bool("resolved", false, "show: list every value together with where it came from")
+	profile := flags.String("profile", "", "show, get, list, validate: apply `name`d profile")
+	project := flags.Bool("project", false, "set, unset, edit, migrate: change the project .g8t.yml instead of ~/.g8t.yml")
+	reveal := flags.Bool("reveal", false, "get, list: print secrets instead of masking them")
+	dryRun := flags.Bool("dry-run", false, "migrate: only show the changes")
+	flags.footer = `Commands:
+  show [--resolved]      Print merged configuration
+  get <key>              Print effective value of a key
+  set <key> <value>      Set a key, lists are written as [a, b]
+  unset <key>            Remove a key
+  list                   Print all effective values as key = value
+  edit                   Open config file in $VISUAL or $EDITOR and validate it
+  validate               Check configuration and resolve secret references
+  migrate [--dry-run]    Update config file to the current format, showing the diff
+  schema                 Print JSON Schema of config files
+  path                   Print location of config files
+`
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		flags.printUsage()
+		return fmt.Errorf("config command is required")
+	}
+
+	command, rest := positional[0], positional[1:]
+	arguments := map[string]int{"show": 0, "get": 1, "set": 2, "unset": 1, "list": 0, "edit": 0, "validate": 0, "path": 0, "migrate": 0, "schema": 0}
+	n, ok := arguments[command]
+	if !ok {
+		flags.printUsage()
+		return fmt.Errorf("unknown config command: %s", command)
+	}
+	if len(rest) != n {
+		flags.printUsage()
+		return fmt.Errorf("config %s takes %d arguments", command, n)
+	}
+
+	switch command {
+	case "path":
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		fmt.Println(path)
+		if wd, err := os.Getwd(); err == nil {
+			if project := config.FindProjectConfig(wd, path); project != "" {
+				fmt.Println(project)
+			}
+		}
+		return nil
+	case "set", "unset":
+		return changeConfig(command, rest, *project, log)
+	case "edit":
+		return editConfig(*project, log)
+	case "migrate":
+		return migrateConfig(*project, *dryRun, log)
+	case "schema":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(config.Schema())
+	}
+
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+	for _, warning := range cfg.Warnings {
+		log.Warning("%s", warning)
+	}
+
+	switch command {
+	case "validate":
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("configuration is invalid: %w", err)
+		}
+		log.Success("Configuration is valid")
+	case "get":
+		if _, err := config.LookupKey(rest[0]); err != nil {
+			return err
+		}
+		values, err := effectiveValues(cfg)
+		if err != nil {
+			return err
+		}
+		value, ok := values[rest[0]]
+		if !ok {
+			return fmt.Errorf("%s is not set", rest[0])
+		}
+		fmt.Println(maskValue(rest[0], value, *reveal))
+	case "list":
+		values, err := effectiveValues(cfg)
+		if err != nil {
+			return err
+		}
+		for _, key := range sortedKeys(values) {
+			fmt.Printf("%s = %s\n", key, maskValue(key, values[key], *reveal))
+		}
+	case "show":
+		maskSecrets(cfg)
+		if *resolved {
+			return showResolved(cfg)
+		}
+
+		encoder := yaml.NewEncoder(os.Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(cfg); err != nil {
+			return fmt.Errorf("failed to marshal config: %w", err)
+		}
+		return encoder.Close()
+	}
+	return nil
+}
+
+// configFile returns user config or the project one to edit
+func configFile(project bool) (string, error) {
+	path, err := config.Path()
+	if err != nil || !project {
+		return path, err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get working directory: %w", err)
+	}
+	if found := config.FindProjectConfig(wd, path); found != "" {
+		return found, nil
+	}
+	return filepath.Join(wd, config.ProjectFile), nil
+}
+
+func changeConfig(command string, args []string, project bool, log logger.Logger) error {
+	path, err := configFile(project)
+	if err != nil {
+		return err
+	}
+	key := args[0]
+	if _, err := config.LookupKey(key); err != nil {
+		return err
+	}
+	if project && !config.ProjectKey(key) {
+		return fmt.Errorf("%s can only be set in the user config", key)
+	}
+
+	file, err := config.OpenFile(path)
+	if err != nil {
+		return err
+	}
+
+	if command == "set" {
+		if err := file.SetString(key, args[1]); err != nil {
+			return err
+		}
+	} else if !file.Unset(key) {
+		return fmt.Errorf("%s is not set in %s", key, path)
+	}
+
+	if err := file.Save(); err != nil {
+		return err
+	}
+	log.Success("Updated %s", path)
+	return nil
+}
+
+func editConfig(project bool, log logger.Logger) error {
+	path, err := configFile(project)
+	if err != nil {
+		return err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor failed: %w", err)
+	}
+
+	if _, err := config.OpenFile(path); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Warning("Configuration is invalid: %v", err)
+		return nil
+	}
+	log.Success("Configuration is valid")
+	return nil
+}
+
+func migrateConfig(project, dryRun bool, log logger.Logger) error {
+	path, err := configFile(project)
+	if err != nil {
+		return err
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	file, err := config.OpenFile(path)
+	if err != nil {
+		return err
+	}
+	changes, err := file.Migrate()
+	if err != nil {
+		return err
+	}
+	if changes == nil {
+		log.Success("%s is already at version %d", path, config.Version)
+		return nil
+	}
+	migrated, err := file.Bytes()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		log.Info("%s", change)
+	}
+	fmt.Print(config.Diff(string(original), string(migrated)))
+	if dryRun {
+		return nil
+	}
+
+	// Keep the original around in case something went wrong
+	if err := os.WriteFile(path+".bak", original, 0600); err != nil {
+		return fmt.Errorf("failed to back up config file: %w", err)
+	}
+	if err := file.Save(); err != nil {
+		return err
+	}
+	log.Success("Migrated %s to version %d, the old file is saved as %s.bak", path, config.Version, path)
+	return nil
+}
+
+// effectiveValues flattens config and adds defaults of configured providers
+func effectiveValues(cfg *config.Config) (map[string]string, error) {
+	_, values, err := cfg.Flatten()
+	if err != nil {
+		return nil, err
+	}
+	for name := range cfg.Providers {
+		for key, value := range cfg.ProviderSettings(name) {
+			values["providers."+name+"."+key] = value
+		}
+	}
+	if _, ok := cfg.Providers[cfg.Provider]; !ok {
+		for key, value := range cfg.ProviderSettings(cfg.Provider) {
+			values["providers."+cfg.Provider+"."+key] = value
+		}
+	}
+	return values, nil
+}
+
+func maskValue(key, value string, reveal bool) string {
+	k, err := config.LookupKey(key)
+	if reveal || err != nil || !k.Secret || value == "" || secret.IsReference(value) {
+		return value
+	}
+	return "********"
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// showResolved prints a line per value with the layer it came from
+func showResolved(cfg *config.Config) error {
+	keys, values, err := cfg.Flatten()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tSOURCE")
+	for _, key := range keys {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key, truncate(values[key], 60), cfg.Source(key))
+	}
+	return tw.Flush()
+}
+
+// maskSecrets hides values of secret provider fields, references like
+// env:NAME are shown since they reveal nothing
+func maskSecrets(cfg *config.Config) {
+	for name, pc := range cfg.Providers {
+		provider, ok := gpt.Lookup(name)
+		if !ok || pc == nil {
+			continue
+		}
+		for key, value := range pc.Settings {
+			if field, ok := provider.Field(key); ok && field.Secret && value != "" && value != field.Default && !secret.IsReference(value) {
+				pc.Settings[key] = "********"
+			}
+		}
+	}
+
+	// Profiles may carry their own provider credentials
+	for _, profile := range cfg.Profiles {
+		providers, _ := profile["providers"].(map[string]interface{})
+		for name, settings := range providers {
+			provider, ok := gpt.Lookup(name)
+			values, isMap := settings.(map[string]interface{})
+			if !ok || !isMap {
+				continue
+			}
+			for key, value := range values {
+				if field, ok := provider.Field(key); ok && field.Secret && value != "" && !secret.IsReference(fmt.Sprint(value)) {
+					values[key] = "********"
+				}
+			}
+		}
+	}
+}
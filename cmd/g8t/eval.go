@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/eval"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+func runEval(args []string, log logger.Logger) error {
+	flags := newFlagSet("eval", "g8t eval [options] suite.yml",
+		"Runs every task of the suite against every provider and model of its matrix\n"+
+			"and prints pass rate, steps, tokens and time of each combination.")
+	jsonPath := flags.String("json", "", "Write JSON report to `file`")
+	verbose := flags.Bool("verbose", false, "Show agent output of every run")
+	flags.alias("v", "verbose")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		flags.printUsage()
+		return fmt.Errorf("suite file is required")
+	}
+
+	suite, err := eval.LoadSuite(positional[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+
+	runner := &eval.Runner{
+		Config:  cfg,
+		Verbose: *verbose,
+		Progress: func(result eval.Result) {
+			if result.Passed {
+				log.Success("%s on %s (run %d): %d steps, %d tokens, %.1fs",
+					result.Task, result.Target, result.Run, result.Steps, result.Tokens, result.Seconds)
+			} else {
+				log.Warning("%s on %s (run %d) failed: %s", result.Task, result.Target, result.Run, result.Error)
+			}
+		},
+	}
+	if *verbose {
+		runner.Log = os.Stdout
+	}
+
+	report := runner.Run(suite)
+
+	fmt.Println()
+	if err := report.WriteTable(os.Stdout); err != nil {
+		return err
+	}
+
+	if *jsonPath != "" {
+		file, err := os.Create(*jsonPath)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer file.Close()
+
+		if err := report.WriteJSON(file); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	return nil
+}
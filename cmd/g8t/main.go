@@ -1,34 +1,91 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"strings"
 
-	"github.com/d1nch8g/g8t/agent"
-	"github.com/d1nch8g/g8t/config"
 	"github.com/d1nch8g/g8t/logger"
 )
 
+// command is a g8t subcommand, run receives arguments after its name
+type command struct {
+	name        string
+	description string
+	run         func(args []string, log logger.Logger) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", "Run a task (default command)", runTask},
+		{"ask", "Answer a question using read-only commands", runAsk},
+		{"explain", "Explain a command, file or error using read-only commands", runExplain},
+		{"setup", "Configure provider and general settings interactively", runSetup},
+		{"config", "Show configuration and its location", runConfig},
+		{"sessions", "List and inspect saved runs", runSessions},
+		{"report", "Export a saved run as Markdown or HTML", runReport},
+		{"models", "List models available from a provider", runModels},
+		{"eval", "Evaluate providers and models on a task suite", runEval},
+		{"cache", "Inspect or clear the response cache", runCache},
+		{"audit", "Verify integrity of the audit log", runAudit},
+		{"help", "Show help of a command", runHelp},
+	}
+}
+
 func main() {
-	// Parse configuration
-	cfg, err := config.Parse()
-	if err != nil {
-		logger := logger.New(false, false)
-		logger.Error("Error parsing configuration: %v", err)
+	log := logger.New(false, false)
+
+	name, args := "run", os.Args[1:]
+	if len(args) > 0 {
+		switch {
+		case args[0] == "--setup":
+			// Kept for compatibility with older versions
+			name, args = "setup", args[1:]
+		case findCommand(args[0]) != nil:
+			name, args = args[0], args[1:]
+		}
+	}
+
+	if err := findCommand(name).run(args, log); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Error("%v", err)
 		os.Exit(1)
 	}
+}
 
-	// Create logger with config settings
-	log := logger.New(cfg.Verbose, cfg.Quiet)
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+	}
+	return nil
+}
 
-	// Create and run agent
-	agentInstance, err := agent.New(cfg, log)
-	if err != nil {
-		log.Error("Failed to create agent: %v", err)
-		os.Exit(1)
+// commandList describes all commands for help output
+func commandList() string {
+	var b strings.Builder
+	b.WriteString("Commands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(&b, "  %-10s %s\n", c.name, c.description)
 	}
+	b.WriteString("\nRun 'g8t help <command>' for details of a command.\n")
+	return b.String()
+}
 
-	if err := agentInstance.Run(cfg.Task); err != nil {
-		log.Error("Agent execution failed: %v", err)
-		os.Exit(1)
+func runHelp(args []string, log logger.Logger) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runTask([]string{"--help"}, log)
+	}
+	c := findCommand(args[0])
+	if c == nil {
+		return fmt.Errorf("unknown command: %s", args[0])
 	}
+	return c.run([]string{"--help"}, log)
 }
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+func runModels(args []string, log logger.Logger) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+
+	flags := newFlagSet("models", "g8t models [--provider name]",
+		"Lists models available to the configured account of a provider.")
+	flags.StringVar(&cfg.Provider, "provider", cfg.Provider, "AI `provider` ("+strings.Join(gpt.Names(), ", ")+")")
+	flags.alias("p", "provider")
+	if _, err := flags.parse(args); err != nil {
+		return err
+	}
+
+	settings, err := cfg.ResolvedSettings(cfg.Provider)
+	if err != nil {
+		return err
+	}
+	client, err := gpt.New(cfg.Provider, settings, gpt.Options{})
+	if err != nil {
+		return err
+	}
+	lister, ok := client.(gpt.ModelLister)
+	if !ok {
+		return fmt.Errorf("%s provider does not support listing models", cfg.Provider)
+	}
+
+	models, err := lister.ListModels()
+	if err != nil {
+		return fmt.Errorf("failed to list models: %w", err)
+	}
+	for _, model := range models {
+		fmt.Println(model)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/d1nch8g/g8t/logger"
+	"github.com/d1nch8g/g8t/session"
+)
+
+func runReport(args []string, log logger.Logger) error {
+	flags := newFlagSet("report", "g8t report [options] <id|last>",
+		"Renders a saved run as Markdown or a self-contained HTML page to share it.\n"+
+			"The format follows the extension of --output, Markdown is the default.")
+	format := flags.String("format", "", "Report `format`, markdown or html")
+	output := flags.String("output", "", "Write report to `file` instead of stdout")
+	flags.alias("f", "format")
+	flags.alias("o", "output")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		flags.printUsage()
+		return fmt.Errorf("session id is required")
+	}
+
+	if *format == "" {
+		*format = "markdown"
+		switch strings.ToLower(filepath.Ext(*output)) {
+		case ".html", ".htm":
+			*format = "html"
+		}
+	}
+	var write func(s *session.Session, w io.Writer) error
+	switch *format {
+	case "markdown", "md":
+		write = (*session.Session).WriteMarkdown
+	case "html":
+		write = (*session.Session).WriteHTML
+	default:
+		return fmt.Errorf("unsupported report format: %s", *format)
+	}
+
+	dir, err := session.Dir()
+	if err != nil {
+		return err
+	}
+	s, err := session.Load(dir, positional[0])
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return write(s, os.Stdout)
+	}
+	file, err := os.Create(*output)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := write(s, file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	log.Success("Report of session %s written to %s", s.ID, *output)
+	return nil
+}
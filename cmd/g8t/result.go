@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/d1nch8g/g8t/agent"
+	"github.com/d1nch8g/g8t/session"
+)
+
+// runResult is written to --result-file, automation reads outcome of a
+// run from it instead of parsing the output
+type runResult struct {
+	Status  string `json:"status"`
+	Session string `json:"session,omitempty"`
+	agent.Result
+	Answer       string `json:"answer,omitempty"`
+	Error        string `json:"error,omitempty"`
+	Steps        int    `json:"steps"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	TotalTokens  int    `json:"total_tokens"`
+	DurationMS   int64  `json:"duration_ms"`
+}
+
+// newRunResult collects outcome of a finished agent run
+func newRunResult(a *agent.Agent, id string, runErr error) runResult {
+	stats := a.Stats()
+	result := runResult{
+		Status:       session.StatusCompleted,
+		Session:      id,
+		Result:       a.Result(),
+		Answer:       a.Answer(),
+		Steps:        stats.Steps,
+		InputTokens:  stats.Usage.InputTokens,
+		OutputTokens: stats.Usage.OutputTokens,
+		TotalTokens:  stats.Usage.TotalTokens,
+		DurationMS:   stats.Duration.Milliseconds(),
+	}
+	if runErr != nil {
+		result.Status = session.StatusFailed
+		result.Error = a.Redact(runErr.Error())
+	}
+	return result
+}
+
+// writeResult replaces file at path with result, the file is renamed into
+// place so readers never see a partial one
+func writeResult(path string, result runResult) error {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write result file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write result file: %w", err)
+	}
+	return nil
+}
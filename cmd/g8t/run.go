@@ -0,0 +1,336 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/d1nch8g/g8t/agent"
+	"github.com/d1nch8g/g8t/audit"
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+	"github.com/d1nch8g/g8t/session"
+	"github.com/d1nch8g/g8t/tracing"
+	"github.com/d1nch8g/g8t/tui"
+)
+
+// settingsHelp describes where settings of a run come from
+const settingsHelp = "Options may appear anywhere, words after -- are always part of the task.\n" +
+	"Settings come from ~/.g8t.yml, the project .g8t.yml, the selected profile,\n" +
+	"G8T_* environment variables and options, later ones win."
+
+func runTask(args []string, log logger.Logger) error {
+	return runMode(args, log, "")
+}
+
+func runAsk(args []string, log logger.Logger) error {
+	return runMode(args, log, config.ModeAsk)
+}
+
+func runExplain(args []string, log logger.Logger) error {
+	return runMode(args, log, config.ModeExplain)
+}
+
+// runMode runs task in given mode, read-only modes finish with an answer
+// which is the only output on stdout when it is not a terminal
+func runMode(args []string, log logger.Logger, mode string) (err error) {
+	var flags *flagSet
+	switch mode {
+	case config.ModeAsk:
+		flags = newFlagSet("ask", "g8t ask [options] [--] <question>",
+			"Answers a question about this system. The assistant may only run commands\n"+
+				"that read information and finishes with a written answer.\n"+settingsHelp)
+	case config.ModeExplain:
+		flags = newFlagSet("explain", "g8t explain [options] [--] <subject>",
+			"Explains a command, file, error or setup, looking up details on this system\n"+
+				"with commands that only read information.\n"+settingsHelp)
+	default:
+		flags = newFlagSet("run", "g8t [run] [options] [--] <task>",
+			"Executes a task by letting an AI assistant run shell commands.\n"+settingsHelp)
+	}
+	profile := flags.String("profile", "", "Apply `name`d profile of the user config")
+	provider := flags.String("provider", "", "AI `provider` ("+strings.Join(gpt.Names(), ", ")+")")
+	model := flags.String("model", os.Getenv(config.EnvPrefix+"MODEL"), "Override `model` of the selected provider")
+	maxCommands := flags.Int("max-commands", 0, "Maximum `number` of commands to execute")
+	verbose := flags.Bool("verbose", false, "Enable verbose output")
+	quiet := flags.Bool("quiet", false, "Suppress non-essential output")
+	dryRun := flags.Bool("dry-run", false, "Show commands without executing them")
+	noCache := flags.Bool("no-cache", false, "Do not use cached responses for this run")
+	output := flags.String("output", "", "Output `format`, text or json for NDJSON events on stdout")
+	tuiMode := flags.Bool("tui", false, "Show full screen terminal UI with pause, approve, skip and abort keys")
+	resultFile := flags.String("result-file", "", "Write summary, changed files, follow-ups and data of the run as JSON to `file`")
+	var attachments stringList
+	flags.Var(&attachments, "attach", "Attach image or text `file` to the task, can be repeated")
+	flags.alias("p", "provider")
+	flags.alias("m", "max-commands")
+	flags.alias("v", "verbose")
+	flags.alias("q", "quiet")
+	flags.alias("d", "dry-run")
+	flags.alias("a", "attach")
+	flags.alias("o", "output")
+	flags.footer = commandList()
+
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	// JSON consumers and result files always get an outcome, errors
+	// before the agent starts are reported here, the agent reports its own
+	format := *output
+	reported := false
+	defer func() {
+		if err == nil || reported {
+			return
+		}
+		if format == config.OutputJSON {
+			logger.NewWithSinks(logger.NewJSON(os.Stdout, logger.LevelNotice)).RunFinished(logger.RunSummary{Error: err.Error()})
+		}
+		if *resultFile != "" {
+			if writeErr := writeResult(*resultFile, runResult{Status: session.StatusFailed, Error: err.Error()}); writeErr != nil {
+				log.Warning("%v", writeErr)
+			}
+		}
+	}()
+	task := strings.Join(positional, " ")
+	if task == "" {
+		flags.printUsage()
+		switch mode {
+		case config.ModeAsk:
+			return fmt.Errorf("question is required")
+		case config.ModeExplain:
+			return fmt.Errorf("subject to explain is required")
+		}
+		return fmt.Errorf("task description is required")
+	}
+
+	cfg, err := config.LoadProfile(*profile)
+	if err != nil {
+		return err
+	}
+	if err := cfg.ApplyEnv(); err != nil {
+		return err
+	}
+
+	// Options override every config layer, only the ones given are applied
+	cfg.Task = task
+	cfg.Mode = mode
+	cfg.Attachments = attachments
+	if flags.isSet("provider") {
+		cfg.Provider = *provider
+		cfg.SetSource("provider", "flag --provider")
+	}
+	if flags.isSet("max-commands") {
+		cfg.MaxCommands = *maxCommands
+		cfg.SetSource("max_commands", "flag --max-commands")
+	}
+	if flags.isSet("verbose") {
+		cfg.Verbose = *verbose
+	}
+	if flags.isSet("quiet") {
+		cfg.Quiet = *quiet
+	}
+	if flags.isSet("dry-run") {
+		cfg.DryRun = *dryRun
+	}
+	if flags.isSet("no-cache") {
+		cfg.NoCache = *noCache
+	}
+	if flags.isSet("output") {
+		cfg.Output = *output
+		cfg.SetSource("output", "flag --output")
+	}
+	format = cfg.Output
+	if *model != "" {
+		cfg.SetProviderSetting(cfg.Provider, "model", *model)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		if config.Exists() || !isTerminal(os.Stdin) || cfg.Output == config.OutputJSON {
+			return fmt.Errorf("configuration validation failed: %w", err)
+		}
+		// First run without a config file, ask for settings and
+		// keep options given on the command line
+		if _, err := config.Setup(); err != nil {
+			return err
+		}
+		reported = true
+		return runMode(args, log, mode)
+	}
+
+	var ui *tui.UI
+	if *tuiMode {
+		if cfg.Output == config.OutputJSON {
+			return fmt.Errorf("--tui cannot be combined with JSON output")
+		}
+		if !logger.IsTerminal(os.Stdout) {
+			return fmt.Errorf("--tui requires a terminal")
+		}
+		ui = tui.New()
+	}
+
+	runLog, err := openLogger(cfg, ui)
+	if err != nil {
+		return err
+	}
+	defer runLog.Close()
+	log = runLog
+
+	var tracer *tracing.Tracer
+	if cfg.Tracing.File != "" || cfg.Tracing.Endpoint != "" {
+		tracer = tracing.NewTracer(cfg.Tracing.File, cfg.Tracing.Endpoint)
+		runLog.AddSink(tracer)
+	}
+	started := time.Now()
+	sessionID := session.NewID(started)
+	var auditLog *audit.Log
+	if cfg.AuditLog != "" {
+		if auditLog, err = audit.Open(cfg.AuditLog, sessionID, cfg.WorkDir); err != nil {
+			return err
+		}
+		runLog.AddSink(auditLog)
+	}
+	for _, warning := range cfg.Warnings {
+		log.Warning("%s", warning)
+	}
+
+	agentInstance, err := agent.New(cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to create agent: %w", err)
+	}
+
+	reported = true
+	var runErr error
+	if ui != nil {
+		agentInstance.SetGate(ui.Gate)
+		runErr = ui.Run(func(ctx context.Context) error {
+			return agentInstance.RunContext(ctx, cfg.Task)
+		})
+		// The screen is gone once the UI quits, the outcome stays
+		if outcome, ok := ui.Outcome(); ok {
+			logger.NewTerminal(os.Stdout, os.Stderr, logger.ConsoleLevel(cfg.Verbose, cfg.Quiet)).Write(outcome)
+		}
+	} else {
+		// Without a terminal to ask, commands needing approval are refused
+		if cfg.Policy.ApproveRisk != "" && cfg.Output != config.OutputJSON && logger.IsTerminal(os.Stdin) {
+			agentInstance.SetGate(promptGate(os.Stdin, os.Stdout))
+		}
+		runErr = agentInstance.Run(cfg.Task)
+	}
+
+	if tracer != nil {
+		if err := tracer.Flush(); err != nil {
+			log.Warning("%v", err)
+		}
+	}
+
+	if err := saveSession(cfg, agentInstance, sessionID, started, runErr); err != nil {
+		log.Warning("Failed to save session: %v", err)
+	}
+	var resultErr error
+	if *resultFile != "" {
+		resultErr = writeResult(*resultFile, newRunResult(agentInstance, sessionID, runErr))
+	}
+
+	if runErr != nil {
+		if auditLog != nil && auditLog.Err() != nil {
+			log.Error("Audit log is incomplete: %v", auditLog.Err())
+		}
+		if resultErr != nil {
+			log.Error("%v", resultErr)
+		}
+		return fmt.Errorf("agent execution failed: %w", runErr)
+	}
+	if auditLog != nil && auditLog.Err() != nil {
+		return fmt.Errorf("audit log is incomplete: %w", auditLog.Err())
+	}
+	return resultErr
+}
+
+// openLogger creates sinks of a run: terminal UI, terminal or JSON
+// output, the log file and syslog when configured
+func openLogger(cfg *config.Config, ui *tui.UI) (*logger.Dispatcher, error) {
+	level := logger.ConsoleLevel(cfg.Verbose, cfg.Quiet)
+	var console logger.Sink = logger.NewTerminal(os.Stdout, os.Stderr, level)
+	switch {
+	case ui != nil:
+		console = ui
+	case cfg.Output == config.OutputJSON:
+		console = logger.NewJSON(os.Stdout, level)
+	case cfg.ReadOnly() && !logger.IsTerminal(os.Stdout):
+		// Only the answer is piped, progress goes to stderr
+		text := logger.NewTerminal(os.Stderr, os.Stderr, level)
+		text.AnswerOut = os.Stdout
+		console = text
+	}
+	log := logger.NewWithSinks(console)
+
+	if cfg.LogFile != "" {
+		file, err := logger.OpenFileLog(cfg.LogFile)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.LogLevel != "" {
+			file.MinLevel, _ = logger.ParseLevel(cfg.LogLevel)
+		}
+		file.JSON = cfg.LogFormat == config.OutputJSON
+		log.AddSink(file)
+	}
+
+	if cfg.SyslogLevel != "" {
+		level, _ := logger.ParseLevel(cfg.SyslogLevel)
+		sink, err := logger.NewSyslog("g8t", level)
+		if err != nil {
+			log.Close()
+			return nil, err
+		}
+		log.AddSink(sink)
+	}
+
+	return log, nil
+}
+
+func saveSession(cfg *config.Config, a *agent.Agent, id string, started time.Time, runErr error) error {
+	dir, err := session.Dir()
+	if err != nil {
+		return err
+	}
+
+	result := a.Result()
+	s := &session.Session{
+		ID:       id,
+		Task:     a.Redact(cfg.Task),
+		Provider: cfg.Provider,
+		Model:    cfg.ProviderSettings(cfg.Provider)["model"],
+		WorkDir:  cfg.WorkDir,
+		DryRun:   cfg.DryRun,
+		Started:  started,
+		Finished: time.Now(),
+		Status:   session.StatusCompleted,
+		Summary:  a.Summary(),
+		Answer:   a.Answer(),
+		Usage:    a.Stats().Usage,
+		Steps:    a.Steps(),
+
+		FilesChanged: result.FilesChanged,
+		FollowUps:    result.FollowUps,
+		Data:         result.Data,
+	}
+	if s.WorkDir == "" {
+		s.WorkDir, _ = os.Getwd()
+	}
+	if runErr != nil {
+		s.Status = session.StatusFailed
+		s.Error = a.Redact(runErr.Error())
+	}
+
+	return s.Save(dir)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/d1nch8g/g8t/logger"
+	"github.com/d1nch8g/g8t/session"
+)
+
+func runSessions(args []string, log logger.Logger) error {
+	flags := newFlagSet("sessions", "g8t sessions [list] | show <id|last>",
+		"Lists saved runs or shows steps of one of them. Runs are saved to ~/.g8t/sessions,\n"+
+			"an id may be shortened to a unique prefix.")
+	limit := flags.Int("limit", 20, "Show at most `n` sessions, 0 shows all")
+	flags.alias("n", "limit")
+	positional, err := flags.parse(args)
+	if err != nil {
+		return err
+	}
+
+	dir, err := session.Dir()
+	if err != nil {
+		return err
+	}
+
+	if len(positional) == 0 || positional[0] == "list" {
+		sessions, err := session.List(dir)
+		if err != nil {
+			return err
+		}
+		if *limit > 0 && len(sessions) > *limit {
+			sessions = sessions[:*limit]
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tSTARTED\tSTATUS\tSTEPS\tPROVIDER\tTASK")
+		for _, s := range sessions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				s.ID, s.Started.Format("2006-01-02 15:04"), s.Status, len(s.Steps), s.Provider, truncate(s.Task, 50))
+		}
+		return tw.Flush()
+	}
+
+	if positional[0] != "show" || len(positional) != 2 {
+		flags.printUsage()
+		return fmt.Errorf("unknown sessions command: %s", strings.Join(positional, " "))
+	}
+
+	s, err := session.Load(dir, positional[1])
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Session:  %s\n", s.ID)
+	fmt.Printf("Task:     %s\n", s.Task)
+	fmt.Printf("Provider: %s %s\n", s.Provider, s.Model)
+	fmt.Printf("Started:  %s (%s)\n", s.Started.Format(time.RFC3339), s.Finished.Sub(s.Started).Round(time.Second))
+	fmt.Printf("Status:   %s\n", s.Status)
+	if s.Error != "" {
+		fmt.Printf("Error:    %s\n", s.Error)
+	}
+	if s.Summary != "" {
+		fmt.Printf("Summary:  %s\n", s.Summary)
+	}
+	if len(s.FilesChanged) > 0 {
+		fmt.Printf("Files:    %s\n", strings.Join(s.FilesChanged, ", "))
+	}
+	for _, followUp := range s.FollowUps {
+		fmt.Printf("Next:     %s\n", followUp)
+	}
+	if len(s.Data) > 0 {
+		fmt.Printf("Data:     %s\n", s.Data)
+	}
+	if s.Answer != "" {
+		fmt.Printf("Answer:\n%s\n", strings.TrimSpace(s.Answer))
+	}
+	fmt.Printf("Tokens:   %d\n", s.Usage.TotalTokens)
+
+	for _, step := range s.Steps {
+		fmt.Printf("\nStep %d: %s\n$ %s\n", step.Number, step.Thought, step.Command)
+		if step.Output != "" {
+			fmt.Print(step.Output)
+			if !strings.HasSuffix(step.Output, "\n") {
+				fmt.Println()
+			}
+		}
+		if step.Error != "" {
+			fmt.Printf("Error: %s\n", step.Error)
+		}
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	s = strings.ReplaceAll(s, "\n", " ")
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
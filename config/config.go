@@ -3,54 +3,173 @@ package config
 import (
 	"bufio"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+	"github.com/d1nch8g/g8t/redact"
+	"github.com/d1nch8g/g8t/secret"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	// Provider settings
-	Provider string `yaml:"provider"`
-
-	// Yandex GPT settings
-	FolderID string `yaml:"folder_id"`
-	IAMToken string `yaml:"iam_token"`
-
-	// OpenAI settings
-	OpenAIKey   string `yaml:"openai_key"`
-	OpenAIModel string `yaml:"openai_model"`
+	// Version of config format, see Version
+	Version int `yaml:"version"`
 
-	// DeepSeek settings
-	DeepSeekKey   string `yaml:"deepseek_key"`
-	DeepSeekModel string `yaml:"deepseek_model"`
-
-	// Claude settings
-	ClaudeKey   string `yaml:"claude_key"`
-	ClaudeModel string `yaml:"claude_model"`
+	// Provider settings
+	Provider  string                     `yaml:"provider"`
+	Providers map[string]*ProviderConfig `yaml:"providers"`
 
-	// Gemini settings
-	GeminiKey   string `yaml:"gemini_key"`
-	GeminiModel string `yaml:"gemini_model"`
+	// Profile names the profile applied on top of config files,
+	// Profiles are defined in the user config only
+	Profile  string                            `yaml:"profile,omitempty"`
+	Profiles map[string]map[string]interface{} `yaml:"profiles,omitempty"`
 
-	// Ollama settings
-	OllamaURL   string `yaml:"ollama_url"`
-	OllamaModel string `yaml:"ollama_model"`
+	// Generation options applied to any provider
+	Generation gpt.Options `yaml:"generation,omitempty"`
 
 	// Task settings (not saved to config, passed as args)
-	Task        string `yaml:"-"`
-	MaxCommands int    `yaml:"max_commands"`
+	Task        string   `yaml:"-"`
+	Attachments []string `yaml:"-"`
+	WorkDir     string   `yaml:"-"`
+	MaxCommands int      `yaml:"max_commands"`
+	// Mode is ModeAsk or ModeExplain for read-only runs answering the
+	// task, empty mode carries the task out
+	Mode string `yaml:"-"`
+
+	// SystemPrompt is appended to the built-in instructions
+	SystemPrompt string `yaml:"system_prompt,omitempty"`
+	// CommandTimeout limits a single command, e.g. 30s or 5m
+	CommandTimeout string `yaml:"command_timeout,omitempty"`
+	Policy         Policy `yaml:"policy,omitempty"`
 
 	// Output settings
 	Verbose bool   `yaml:"verbose"`
 	Quiet   bool   `yaml:"quiet"`
 	DryRun  bool   `yaml:"dry_run"`
 	LogFile string `yaml:"log_file"`
+	// LogLevel filters log file entries, LogFormat is text or json
+	LogLevel  string `yaml:"log_level,omitempty"`
+	LogFormat string `yaml:"log_format,omitempty"`
+	// SyslogLevel enables system log entries of that level and above
+	SyslogLevel string `yaml:"syslog_level,omitempty"`
+	// Output is text for people or json for NDJSON events
+	Output string `yaml:"output,omitempty"`
+	// AuditLog is a hash-chained record of every proposed command
+	AuditLog string `yaml:"audit_log,omitempty"`
+
+	// Response cache settings, NoCache disables cache for a single run
+	Cache   CacheConfig `yaml:"cache,omitempty"`
+	NoCache bool        `yaml:"-"`
+
+	Tracing TracingConfig `yaml:"tracing,omitempty"`
+
+	Redaction RedactionConfig `yaml:"redaction,omitempty"`
+
+	// Sources maps dotted keys to the layer that set them, Warnings
+	// collects problems found while loading
+	Sources  map[string]string `yaml:"-"`
+	Warnings []string          `yaml:"-"`
+}
+
+// ProviderConfig holds settings and generation options of a single provider
+type ProviderConfig struct {
+	Settings   gpt.Settings `yaml:",inline"`
+	Generation gpt.Options  `yaml:"generation,omitempty"`
+}
+
+// CacheConfig controls on-disk cache of provider responses
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir,omitempty"`
+	TTL     string `yaml:"ttl,omitempty"`
+	MaxSize int    `yaml:"max_size_mb,omitempty"`
+}
+
+// TracingConfig controls export of run traces in OpenTelemetry format,
+// tracing is enabled when any destination is set
+type TracingConfig struct {
+	// File receives OTLP-JSON, one export request per line
+	File string `yaml:"file,omitempty"`
+	// Endpoint is base URL of an OTLP/HTTP collector
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+// RedactionConfig controls masking of secrets in commands and outputs
+// before they reach providers and logs, it is on unless disabled
+type RedactionConfig struct {
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Patterns are regular expressions of secrets added to built-in
+	// ones, only the first group is masked when a pattern has groups
+	Patterns []string `yaml:"patterns,omitempty"`
+}
+
+// Output formats of a run
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// Read-only modes of a run
+const (
+	ModeAsk     = "ask"
+	ModeExplain = "explain"
+)
+
+// ReadOnly reports whether the run may only run commands that read
+// information
+func (c *Config) ReadOnly() bool {
+	return c.Mode == ModeAsk || c.Mode == ModeExplain
+}
+
+// Defaults used when config omits a value
+const (
+	defaultCommandTimeout = 30 * time.Second
+	defaultCacheTTL       = 7 * 24 * time.Hour
+	defaultCacheMaxSize   = 100
+)
+
+// Open returns cache described by config, defaults are used for
+// missing directory, TTL and size cap
+func (c CacheConfig) Open() (*gpt.Cache, error) {
+	dir := c.Dir
+	if strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		dir = filepath.Join(home, dir[2:])
+	}
+	if dir == "" {
+		var err error
+		if dir, err = gpt.DefaultCacheDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	ttl := defaultCacheTTL
+	if c.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(c.TTL); err != nil {
+			return nil, fmt.Errorf("invalid cache ttl %q: %w", c.TTL, err)
+		}
+	}
+
+	maxSize := c.MaxSize
+	if maxSize == 0 {
+		maxSize = defaultCacheMaxSize
+	}
+
+	return gpt.NewCache(dir, ttl, int64(maxSize)*1024*1024), nil
 }
 
-func getConfigPath() (string, error) {
+// Path returns location of the user config file
+func Path() (string, error) {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
@@ -59,7 +178,7 @@ func getConfigPath() (string, error) {
 }
 
 func (c *Config) Save() error {
-	configPath, err := getConfigPath()
+	configPath, err := Path()
 	if err != nil {
 		return err
 	}
@@ -77,27 +196,88 @@ func (c *Config) Save() error {
 	return nil
 }
 
-func loadConfig() (*Config, error) {
-	configPath, err := getConfigPath()
-	if err != nil {
-		return nil, err
+// provider returns config of named provider, creating it when missing
+func (c *Config) provider(name string) *ProviderConfig {
+	if c.Providers == nil {
+		c.Providers = map[string]*ProviderConfig{}
+	}
+	pc := c.Providers[name]
+	if pc == nil {
+		pc = &ProviderConfig{}
+		c.Providers[name] = pc
 	}
+	if pc.Settings == nil {
+		pc.Settings = gpt.Settings{}
+	}
+	return pc
+}
 
-	data, err := os.ReadFile(configPath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil // Config doesn't exist
+// ProviderSettings returns settings of named provider with defaults applied
+func (c *Config) ProviderSettings(name string) gpt.Settings {
+	var settings gpt.Settings
+	if pc := c.Providers[name]; pc != nil {
+		settings = pc.Settings
+	}
+
+	provider, ok := gpt.Lookup(name)
+	if !ok {
+		return settings
+	}
+	return provider.WithDefaults(settings)
+}
+
+// ResolvedSettings returns settings of named provider with secret
+// references like env:NAME replaced by their values
+func (c *Config) ResolvedSettings(name string) (gpt.Settings, error) {
+	settings := c.ProviderSettings(name)
+	provider, ok := gpt.Lookup(name)
+	if !ok {
+		return settings, nil
+	}
+
+	resolved := gpt.Settings{}
+	for key, value := range settings {
+		if field, ok := provider.Field(key); ok && field.Secret {
+			v, err := secret.Resolve(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s of %s provider: %w", key, name, err)
+			}
+			value = v
 		}
-		return nil, fmt.Errorf("failed to read config file: %w", err)
+		resolved[key] = value
 	}
+	return resolved, nil
+}
 
-	var config Config
-	err = yaml.Unmarshal(data, &config)
+// GenerationOptions returns generation options for named provider,
+// provider level options take precedence over global ones
+func (c *Config) GenerationOptions(name string) gpt.Options {
+	options := c.Generation
+	if pc := c.Providers[name]; pc != nil {
+		options = options.Merge(pc.Generation)
+	}
+	return options
+}
+
+// Load reads user and project config with the profile selected by
+// G8T_PROFILE or config, command line arguments are handled by the caller
+func Load() (*Config, error) {
+	return LoadProfile("")
+}
+
+// Exists reports whether the user config file exists
+func Exists() bool {
+	configPath, err := Path()
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+		return false
 	}
+	_, err = os.Stat(configPath)
+	return err == nil
+}
 
-	return &config, nil
+// SetProviderSetting sets a single setting of named provider
+func (c *Config) SetProviderSetting(name, key, value string) {
+	c.provider(name).Settings[key] = value
 }
 
 func promptString(prompt, defaultValue string) string {
@@ -136,33 +316,12 @@ func promptInt(prompt string, defaultValue int) int {
 }
 
 func newConfigWithDefaults() *Config {
-	return &Config{
-		// Provider defaults
-		Provider: "openai",
-
-		// Yandex defaults
-		FolderID: "your-folder-id",
-		IAMToken: "your-iam-token",
-
-		// OpenAI defaults
-		OpenAIKey:   "your-openai-key",
-		OpenAIModel: "gpt-3.5-turbo",
-
-		// DeepSeek defaults
-		DeepSeekKey:   "your-deepseek-key",
-		DeepSeekModel: "deepseek-chat",
+	config := &Config{
+		Version: Version,
 
-		// Claude defaults
-		ClaudeKey:   "your-claude-key",
-		ClaudeModel: "claude-3-sonnet-20240229",
-
-		// Gemini defaults
-		GeminiKey:   "your-gemini-key",
-		GeminiModel: "gemini-pro",
-
-		// Ollama defaults
-		OllamaURL:   "http://localhost:11434",
-		OllamaModel: "llama2",
+		// Provider defaults
+		Provider:  "openai",
+		Providers: map[string]*ProviderConfig{},
 
 		// General defaults
 		MaxCommands: 20,
@@ -171,11 +330,22 @@ func newConfigWithDefaults() *Config {
 		DryRun:      false,
 		LogFile:     "",
 	}
+
+	// Keep defaults of every provider so switching is a matter of editing the file
+	for _, provider := range gpt.Providers() {
+		if !provider.Hidden {
+			config.Providers[provider.Name] = &ProviderConfig{Settings: provider.Defaults()}
+		}
+	}
+
+	return config
 }
 
-func setupConfig() {
+// Setup interactively asks for provider and general settings
+// and saves them to the user config file
+func Setup() (*Config, error) {
 	fmt.Println("Welcome to g8t! Let's set up your configuration.")
-	fmt.Println("Supported providers: yandex, openai, deepseek, claude, gemini, ollama")
+	fmt.Printf("Supported providers: %s\n", strings.Join(gpt.Names(), ", "))
 
 	config := newConfigWithDefaults()
 
@@ -183,25 +353,23 @@ func setupConfig() {
 	config.Provider = promptString("Select GPT provider", config.Provider)
 
 	// Configure selected provider
-	switch config.Provider {
-	case "yandex":
-		config.FolderID = promptString("Yandex Cloud Folder ID", config.FolderID)
-		config.IAMToken = promptString("Yandex Cloud IAM Token", config.IAMToken)
-	case "openai":
-		config.OpenAIKey = promptString("OpenAI API Key", config.OpenAIKey)
-		config.OpenAIModel = promptString("OpenAI Model", config.OpenAIModel)
-	case "deepseek":
-		config.DeepSeekKey = promptString("DeepSeek API Key", config.DeepSeekKey)
-		config.DeepSeekModel = promptString("DeepSeek Model", config.DeepSeekModel)
-	case "claude":
-		config.ClaudeKey = promptString("Claude API Key", config.ClaudeKey)
-		config.ClaudeModel = promptString("Claude Model", config.ClaudeModel)
-	case "gemini":
-		config.GeminiKey = promptString("Gemini API Key", config.GeminiKey)
-		config.GeminiModel = promptString("Gemini Model", config.GeminiModel)
-	case "ollama":
-		config.OllamaURL = promptString("Ollama API URL", config.OllamaURL)
-		config.OllamaModel = promptString("Ollama Model", config.OllamaModel)
+	if provider, ok := gpt.Lookup(config.Provider); ok {
+		settings := config.provider(provider.Name).Settings
+		keyring := secret.KeyringAvailable()
+		for _, field := range provider.Fields {
+			value := promptString(field.Prompt, settings[field.Name])
+			// Keep plaintext keys out of the config file when possible
+			if field.Secret && keyring && value != "" && value != field.Default && !secret.IsReference(value) &&
+				promptBool("Store it in the system keyring instead of ~/.g8t.yml", true) {
+				ref, err := secret.Store(provider.Name+"-"+field.Name, value)
+				if err != nil {
+					fmt.Printf("%v, the value is saved to the config file\n", err)
+				} else {
+					value = ref
+				}
+			}
+			settings[field.Name] = value
+		}
 	}
 
 	// General settings
@@ -212,15 +380,75 @@ func setupConfig() {
 	config.LogFile = promptString("Log file path (optional)", config.LogFile)
 
 	// Save configuration
-	err := config.Save()
-	if err != nil {
-		fmt.Printf("Failed to save configuration: %v\n", err)
-		os.Exit(1)
+	if err := config.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save configuration: %w", err)
 	}
 
 	fmt.Println("Configuration saved to ~/.g8t.yml")
 	fmt.Println("You can edit ~/.g8t.yml to switch providers or update settings anytime.")
-	os.Exit(0)
+	return config, nil
+}
+
+// Timeout returns limit of a single command, 30 seconds by default
+func (c *Config) Timeout() (time.Duration, error) {
+	if c.CommandTimeout == "" {
+		return defaultCommandTimeout, nil
+	}
+	timeout, err := time.ParseDuration(c.CommandTimeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid command_timeout %q", c.CommandTimeout)
+	}
+	return timeout, nil
+}
+
+// SetupNonInteractive creates user config from G8T_* environment
+// variables and key=value assignments, used to provision machines
+func SetupNonInteractive(assignments []string) (*Config, error) {
+	config := newConfigWithDefaults()
+	if err := config.ApplyEnv(); err != nil {
+		return nil, err
+	}
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	file := &File{Path: path}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	file.root = doc.Content[0]
+
+	for _, assignment := range assignments {
+		key, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid assignment %q, expected key=value", assignment)
+		}
+		if err := file.SetString(key, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if data, err = file.Bytes(); err != nil {
+		return nil, err
+	}
+	config = &Config{}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	if err := file.Save(); err != nil {
+		return nil, err
+	}
+	return config, nil
 }
 
 func (c *Config) GetLogLevel() string {
@@ -234,117 +462,62 @@ func (c *Config) GetLogLevel() string {
 }
 
 func (c *Config) Validate() error {
-	switch c.Provider {
-	case "yandex":
-		if c.FolderID == "your-folder-id" || c.IAMToken == "your-iam-token" {
-			return fmt.Errorf("yandex provider requires valid folder-id and iam-token")
-		}
-	case "openai":
-		if c.OpenAIKey == "your-openai-key" {
-			return fmt.Errorf("openai provider requires valid openai-key")
-		}
-	case "deepseek":
-		if c.DeepSeekKey == "your-deepseek-key" {
-			return fmt.Errorf("deepseek provider requires valid deepseek-key")
-		}
-	case "claude":
-		if c.ClaudeKey == "your-claude-key" {
-			return fmt.Errorf("claude provider requires valid claude-key")
-		}
-	case "gemini":
-		if c.GeminiKey == "your-gemini-key" {
-			return fmt.Errorf("gemini provider requires valid gemini-key")
-		}
-	case "ollama":
-		if c.OllamaURL == "" || c.OllamaModel == "your-ollama-model" {
-			return fmt.Errorf("ollama provider requires valid ollama-url and ollama-model")
-		}
-	default:
+	provider, ok := gpt.Lookup(c.Provider)
+	if !ok {
 		return fmt.Errorf("unsupported provider: %s", c.Provider)
 	}
+	settings, err := c.ResolvedSettings(c.Provider)
+	if err != nil {
+		return err
+	}
+	if err := provider.Check(settings); err != nil {
+		return err
+	}
+	if err := provider.CheckOptions(c.GenerationOptions(c.Provider)); err != nil {
+		return fmt.Errorf("invalid generation options: %w", err)
+	}
 
 	if c.MaxCommands <= 0 {
 		return fmt.Errorf("max-commands must be greater than 0")
 	}
 
-	return nil
-}
-
-func Parse() (*Config, error) {
-	// Try to load existing config
-	config, err := loadConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load config: %w", err)
-	}
-
-	// If no config exists, run setup
-	if config == nil {
-		setupConfig()
-	}
-
-	// Parse command line arguments for task
-	args := os.Args[1:]
-	if len(args) == 0 {
-		return nil, fmt.Errorf("task description is required as command line argument")
-	}
-
-	// Join all arguments as the task description
-	config.Task = strings.Join(args, " ")
-
-	// Handle special flags that might override config
-	var newArgs []string
-	for i, arg := range args {
-		switch arg {
-		case "--help", "-h":
-			fmt.Println(`Usage: g8t <task>
-
-Description:
-	g8t is a command-line tool that helps you execute tasks using AI assistants.
-	It supports multiple AI providers and allows configuration of various execution parameters.
-
-Options:
-	--help, -h           Show this help message
-	--verbose, -v        Enable verbose output
-	--quiet, -q          Suppress non-essential output
-	--dry-run, -d        Show commands without executing them
-	--setup              Reconfigure tool settings
-	--max-commands, -m   Maximum number of commands to execute
-	--provider, -p       Specify AI provider (openai, claude, gemini, yandex, ollama)`)
-			os.Exit(0)
-		case "--verbose", "-v":
-			config.Verbose = true
-		case "--quiet", "-q":
-			config.Quiet = true
-		case "--dry-run", "-d":
-			config.DryRun = true
-		case "--setup":
-			setupConfig()
-		case "--max-commands", "-m":
-			if i+1 < len(args) {
-				if val, err := strconv.Atoi(args[i+1]); err == nil {
-					config.MaxCommands = val
-				}
-			}
-		case "--provider", "-p":
-			if i+1 < len(args) {
-				config.Provider = args[i+1]
-			}
-		default:
-			if arg != "--provider" && arg != "-p" {
-				newArgs = append(newArgs, arg)
-			}
-		}
+	if _, err := c.Timeout(); err != nil {
+		return err
+	}
+	if err := c.Policy.Validate(); err != nil {
+		return fmt.Errorf("invalid policy: %w", err)
+	}
+	if _, err := redact.New(c.Redaction.Patterns...); err != nil {
+		return err
 	}
 
-	// Update task with filtered arguments
-	if len(newArgs) > 0 {
-		config.Task = strings.Join(newArgs, " ")
+	if _, err := c.Cache.Open(); err != nil {
+		return err
 	}
 
-	// Validate configuration
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	switch c.Output {
+	case "", OutputText, OutputJSON:
+	default:
+		return fmt.Errorf("unsupported output format: %s", c.Output)
+	}
+	switch c.LogFormat {
+	case "", OutputText, OutputJSON:
+	default:
+		return fmt.Errorf("unsupported log format: %s", c.LogFormat)
+	}
+	if c.Tracing.Endpoint != "" {
+		if u, err := url.Parse(c.Tracing.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid tracing endpoint: %s", c.Tracing.Endpoint)
+		}
+	}
+	for _, level := range []string{c.LogLevel, c.SyslogLevel} {
+		if level == "" {
+			continue
+		}
+		if _, err := logger.ParseLevel(level); err != nil {
+			return err
+		}
 	}
 
-	return config, nil
+	return nil
 }
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/d1nch8g/g8t/gpt"
+)
+
+// EnvPrefix starts names of environment variables overriding config
+const EnvPrefix = "G8T_"
+
+// ApplyEnv overrides config with G8T_* environment variables. General
+// settings use their yaml name (G8T_MAX_COMMANDS), provider settings are
+// prefixed with provider name (G8T_OPENAI_KEY, G8T_OLLAMA_URL)
+func (c *Config) ApplyEnv() error {
+	if value, ok := lookupEnv("provider"); ok {
+		c.Provider = value
+		c.SetSource("provider", "env "+envName("provider"))
+	}
+	strs := map[string]*string{
+		"log_file":     &c.LogFile,
+		"log_level":    &c.LogLevel,
+		"log_format":   &c.LogFormat,
+		"syslog_level": &c.SyslogLevel,
+		"output":       &c.Output,
+		"audit_log":    &c.AuditLog,
+	}
+	for key, target := range strs {
+		if value, ok := lookupEnv(key); ok {
+			*target = value
+			c.SetSource(key, "env "+envName(key))
+		}
+	}
+	if value, ok := lookupEnv("max_commands"); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", envName("max_commands"), err)
+		}
+		c.MaxCommands = n
+		c.SetSource("max_commands", "env "+envName("max_commands"))
+	}
+
+	bools := map[string]*bool{
+		"verbose":  &c.Verbose,
+		"quiet":    &c.Quiet,
+		"dry_run":  &c.DryRun,
+		"no_cache": &c.NoCache,
+	}
+	for key, target := range bools {
+		value, ok := lookupEnv(key)
+		if !ok {
+			continue
+		}
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", envName(key), err)
+		}
+		*target = b
+		c.SetSource(key, "env "+envName(key))
+	}
+
+	for _, provider := range gpt.Providers() {
+		for _, field := range provider.Fields {
+			key := provider.Name + "_" + field.Name
+			if value, ok := lookupEnv(key); ok {
+				c.SetProviderSetting(provider.Name, field.Name, value)
+				c.SetSource("providers."+provider.Name+"."+field.Name, "env "+envName(key))
+			}
+		}
+	}
+
+	return nil
+}
+
+// SetSource records where value of dotted key came from
+func (c *Config) SetSource(key, source string) {
+	if c.Sources == nil {
+		c.Sources = map[string]string{}
+	}
+	c.Sources[key] = source
+}
+
+func envName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+func lookupEnv(key string) (string, bool) {
+	return os.LookupEnv(envName(key))
+}
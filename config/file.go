@@ -0,0 +1,187 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// File is a config file edited in place, comments and order of keys
+// are kept intact
+type File struct {
+	Path string
+	root *yaml.Node
+}
+
+// OpenFile reads config file for editing, a missing file is empty
+// apart from the current version
+func OpenFile(path string) (*File, error) {
+	f := &File{Path: path, root: &yaml.Node{Kind: yaml.MappingNode}}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			f.setVersion()
+			return f, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) > 0 {
+		if doc.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("failed to parse %s: top level must be a mapping", path)
+		}
+		f.root = doc.Content[0]
+	}
+	return f, nil
+}
+
+// Get returns value of dotted key as YAML text
+func (f *File) Get(key string) (string, bool) {
+	node := f.find(key)
+	if node == nil {
+		return "", false
+	}
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, true
+	}
+	data, err := yaml.Marshal(node)
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimSpace(string(data)), true
+}
+
+// Set stores value under dotted key creating missing parents
+func (f *File) Set(key string, value interface{}) error {
+	data, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return fmt.Errorf("failed to encode %s", key)
+	}
+	encoded := *doc.Content[0]
+	if encoded.Kind == yaml.SequenceNode {
+		encoded.Style = yaml.FlowStyle
+	}
+
+	node := f.root
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("cannot set %s, %s is not a mapping", key, strings.Join(parts[:i], "."))
+		}
+		child := mappingValue(node, part)
+		if i == len(parts)-1 {
+			if child != nil {
+				*child = encoded
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, &encoded)
+			}
+			return nil
+		}
+		if child == nil {
+			child = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, child)
+		}
+		node = child
+	}
+	return nil
+}
+
+// SetString type checks value of a known key and stores it
+func (f *File) SetString(key, value string) error {
+	k, err := LookupKey(key)
+	if err != nil {
+		return err
+	}
+	parsed, err := k.Parse(value)
+	if err != nil {
+		return err
+	}
+	return f.Set(key, parsed)
+}
+
+// Unset removes dotted key, parents left empty are removed as well
+func (f *File) Unset(key string) bool {
+	return unset(f.root, strings.Split(key, "."))
+}
+
+func unset(node *yaml.Node, parts []string) bool {
+	if node.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != parts[0] {
+			continue
+		}
+		if len(parts) == 1 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return true
+		}
+		child := node.Content[i+1]
+		if !unset(child, parts[1:]) {
+			return false
+		}
+		if child.Kind == yaml.MappingNode && len(child.Content) == 0 {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+		}
+		return true
+	}
+	return false
+}
+
+// Bytes returns file content as YAML
+func (f *File) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(f.root); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Save writes file back to its path
+func (f *File) Save() error {
+	data, err := f.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(f.Path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+func (f *File) find(key string) *yaml.Node {
+	node := f.root
+	for _, part := range strings.Split(key, ".") {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		node = mappingValue(node, part)
+	}
+	return node
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
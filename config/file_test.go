@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSetUnset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".g8t.yml")
+	writeFile(t, path, "# user config\nprovider: claude # favourite\nproviders:\n  claude:\n    key: ck\n")
+
+	file, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range [][2]string{
+		{"max_commands", "12"},
+		{"providers.claude.model", "claude-3-haiku"},
+		{"generation.stop", "[END, STOP]"},
+		{"providers.openai.generation.seed", "7"},
+	} {
+		if err := file.SetString(kv[0], kv[1]); err != nil {
+			t.Fatalf("set %s: %v", kv[0], err)
+		}
+	}
+	if !file.Unset("providers.openai.generation.seed") || file.Unset("missing.key") {
+		t.Fatal("unexpected unset result")
+	}
+	if err := file.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# user config
+provider: claude # favourite
+providers:
+  claude:
+    key: ck
+    model: claude-3-haiku
+max_commands: 12
+generation:
+  stop: [END, STOP]
+`
+	if string(data) != want {
+		t.Fatalf("unexpected file:\n%s", data)
+	}
+	if value, ok := file.Get("providers.claude.model"); !ok || value != "claude-3-haiku" {
+		t.Fatalf("unexpected value %q", value)
+	}
+}
+
+func TestSetStringChecksTypes(t *testing.T) {
+	file, err := OpenFile(filepath.Join(t.TempDir(), "missing.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := map[string]string{
+		"max_commands":           "many",
+		"verbose":                "sometimes",
+		"command_timeout":        "soon",
+		"generation.temperature": "hot",
+		"policy.deny":            "[(]",
+		"provider":               "unknown",
+		"providers.openai.url2":  "x",
+		"unknown":                "x",
+		"profiles.work":          "x",
+	}
+	for key, value := range invalid {
+		if err := file.SetString(key, value); err == nil {
+			t.Errorf("expected %s=%s to be rejected", key, value)
+		}
+	}
+
+	if err := file.SetString("profiles.work.providers.claude.key", "env:KEY"); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := LookupKey("profiles.work.providers.claude.key"); !key.Secret {
+		t.Error("expected profile provider key to be secret")
+	}
+	if data, _ := file.Bytes(); !strings.Contains(string(data), "key: env:KEY") {
+		t.Errorf("unexpected file:\n%s", data)
+	}
+}
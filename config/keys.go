@@ -0,0 +1,192 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/d1nch8g/g8t/gpt"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is type of a config value
+type Kind string
+
+// Kinds of config values
+const (
+	KindString   Kind = "string"
+	KindInt      Kind = "int"
+	KindFloat    Kind = "float"
+	KindBool     Kind = "bool"
+	KindDuration Kind = "duration"
+	KindList     Kind = "list"
+	KindPatterns Kind = "patterns"
+	KindProvider Kind = "provider"
+)
+
+// Key describes a config key addressed by dotted path
+type Key struct {
+	Path   string
+	Kind   Kind
+	Secret bool
+}
+
+// generalKeys are top level keys, profiles may set any of them as well
+var generalKeys = map[string]Kind{
+	"provider":            KindProvider,
+	"profile":             KindString,
+	"max_commands":        KindInt,
+	"system_prompt":       KindString,
+	"command_timeout":     KindDuration,
+	"policy.deny":         KindPatterns,
+	"policy.allow":        KindPatterns,
+	"policy.max_risk":     KindString,
+	"policy.approve_risk": KindString,
+	"verbose":             KindBool,
+	"quiet":               KindBool,
+	"dry_run":             KindBool,
+	"log_file":            KindString,
+	"log_level":           KindString,
+	"log_format":          KindString,
+	"syslog_level":        KindString,
+	"output":              KindString,
+	"audit_log":           KindString,
+	"cache.enabled":       KindBool,
+	"cache.dir":           KindString,
+	"cache.ttl":           KindDuration,
+	"cache.max_size_mb":   KindInt,
+	"tracing.file":        KindString,
+	"tracing.endpoint":    KindString,
+	"redaction.disabled":  KindBool,
+	"redaction.patterns":  KindPatterns,
+}
+
+var generationKeys = map[string]Kind{
+	gpt.OptionTemperature:     KindFloat,
+	gpt.OptionTopP:            KindFloat,
+	gpt.OptionMaxTokens:       KindInt,
+	gpt.OptionSeed:            KindInt,
+	gpt.OptionStop:            KindList,
+	gpt.OptionReasoningEffort: KindString,
+	gpt.OptionThinkingBudget:  KindInt,
+}
+
+// LookupKey describes dotted key or returns an error for unknown ones
+func LookupKey(path string) (Key, error) {
+	key := Key{Path: path}
+	parts := strings.Split(path, ".")
+
+	// profiles.<name>.<key> accepts whatever the top level does
+	if parts[0] == "profiles" {
+		if len(parts) < 3 || parts[1] == "" {
+			return key, fmt.Errorf("profile key must look like profiles.<name>.<key>")
+		}
+		inner, err := LookupKey(strings.Join(parts[2:], "."))
+		if err != nil {
+			return key, err
+		}
+		inner.Path = path
+		return inner, nil
+	}
+
+	if kind, ok := generalKeys[path]; ok {
+		key.Kind = kind
+		return key, nil
+	}
+
+	if parts[0] == "generation" && len(parts) == 2 {
+		if kind, ok := generationKeys[parts[1]]; ok {
+			key.Kind = kind
+			return key, nil
+		}
+		return key, fmt.Errorf("unknown generation option %s", parts[1])
+	}
+
+	if parts[0] == "providers" && len(parts) >= 3 {
+		provider, ok := gpt.Lookup(parts[1])
+		if !ok {
+			return key, fmt.Errorf("unsupported provider: %s", parts[1])
+		}
+		if parts[2] == "generation" && len(parts) == 4 {
+			if kind, ok := generationKeys[parts[3]]; ok {
+				key.Kind = kind
+				return key, nil
+			}
+			return key, fmt.Errorf("unknown generation option %s", parts[3])
+		}
+		if len(parts) == 3 {
+			if field, ok := provider.Field(parts[2]); ok {
+				key.Kind = KindString
+				key.Secret = field.Secret
+				return key, nil
+			}
+		}
+		return key, fmt.Errorf("%s provider has no setting %s", provider.Name, strings.Join(parts[2:], "."))
+	}
+
+	return key, fmt.Errorf("unknown config key %s", path)
+}
+
+// Parse converts command line value to the type of key
+func (k Key) Parse(value string) (interface{}, error) {
+	switch k.Kind {
+	case KindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer", k.Path)
+		}
+		return n, nil
+	case KindFloat:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", k.Path)
+		}
+		return f, nil
+	case KindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", k.Path)
+		}
+		return b, nil
+	case KindDuration:
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%s must be a positive duration like 30s or 5m", k.Path)
+		}
+		return value, nil
+	case KindProvider:
+		if _, ok := gpt.Lookup(value); !ok {
+			return nil, fmt.Errorf("unsupported provider: %s", value)
+		}
+		return value, nil
+	case KindList, KindPatterns:
+		list, err := parseList(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a value or a [a, b] list: %w", k.Path, err)
+		}
+		if k.Kind == KindPatterns {
+			for _, pattern := range list {
+				if _, err := regexp.Compile(pattern); err != nil {
+					return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+				}
+			}
+		}
+		return list, nil
+	default:
+		return value, nil
+	}
+}
+
+// parseList accepts a YAML flow list or a single item
+func parseList(value string) ([]string, error) {
+	if !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		return []string{value}, nil
+	}
+	var list []string
+	if err := yaml.Unmarshal([]byte(value), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
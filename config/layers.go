@@ -0,0 +1,354 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/d1nch8g/g8t/risk"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFile is name of project config looked up from the working
+// directory towards the filesystem root
+const ProjectFile = ".g8t.yml"
+
+// SourceDefault marks values no layer has set
+const SourceDefault = "default"
+
+// projectKeys lists top level keys a project config may set, provider
+// credentials, endpoints and local paths stay in the user config
+var projectKeys = map[string]bool{
+	"provider":        true,
+	"max_commands":    true,
+	"generation":      true,
+	"system_prompt":   true,
+	"command_timeout": true,
+	"policy":          true,
+	"dry_run":         true,
+}
+
+// ProjectKey reports whether dotted key may be set in a project config
+func ProjectKey(key string) bool {
+	top, _, _ := strings.Cut(key, ".")
+	return projectKeys[top]
+}
+
+// appendKeys are concatenated across layers instead of being replaced,
+// so a project can add instructions and denied commands but not drop
+// the user's ones
+var appendKeys = map[string]bool{
+	"system_prompt": true,
+	"policy.deny":   true,
+}
+
+// strictKeys keep the strictest value when the project layer is merged,
+// the lowest risk level or dry run, so a project can tighten the user's
+// limits but not relax them. Profiles are the user's own and may relax
+// them
+var strictKeys = map[string]bool{
+	"policy.max_risk":     true,
+	"policy.approve_risk": true,
+	"dry_run":             true,
+}
+
+// layer is a parsed config file or profile together with its origin
+type layer struct {
+	source string
+	values map[string]interface{}
+}
+
+// LoadProfile reads user config, project config found from the working
+// directory and the named profile, layered in this order. An empty
+// profile falls back to G8T_PROFILE and then to the profile key of config
+func LoadProfile(profile string) (*Config, error) {
+	userPath, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get working directory: %w", err)
+	}
+	source := "flag --profile"
+	if profile == "" {
+		profile = os.Getenv(EnvPrefix + "PROFILE")
+		source = "env " + envName("profile")
+	}
+
+	config, err := load(userPath, dir, profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if profile != "" {
+		config.SetSource("profile", source)
+	}
+	return config, nil
+}
+
+// FindProjectConfig returns path of the closest project config in dir
+// or its parents, the user config itself is skipped
+func FindProjectConfig(dir, userPath string) string {
+	for {
+		path := filepath.Join(dir, ProjectFile)
+		if path != userPath {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func load(userPath, dir, profile string) (*Config, error) {
+	merged := map[string]interface{}{
+		"version":      Version,
+		"provider":     "openai",
+		"max_commands": 20,
+	}
+	sources := map[string]string{}
+	var warnings []string
+	var narrow []string
+
+	user, userWarnings, err := readLayer(userPath, userPath)
+	if err != nil {
+		return nil, err
+	}
+	warnings = append(warnings, userWarnings...)
+	if user != nil {
+		warnings = append(warnings, unknownKeyWarnings(user)...)
+	}
+	profiles := map[string]interface{}{}
+	if user != nil {
+		if p, ok := user.values["profiles"].(map[string]interface{}); ok {
+			profiles = p
+		}
+		delete(user.values, "profiles")
+		mergeLayer(merged, user.values, "", sources, user.source, false)
+	}
+
+	if path := FindProjectConfig(dir, userPath); path != "" {
+		project, projectWarnings, err := readLayer(path, path)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, projectWarnings...)
+		for key := range project.values {
+			if !projectKeys[key] && key != "version" {
+				warnings = append(warnings, fmt.Sprintf("%s: key %s is only allowed in user config and is ignored", path, key))
+				delete(project.values, key)
+			}
+		}
+		warnings = append(warnings, unknownKeyWarnings(project)...)
+		narrow = projectAllow(project.values)
+		mergeLayer(merged, project.values, "", sources, project.source, true)
+	}
+
+	if profile == "" {
+		profile, _ = merged["profile"].(string)
+	}
+	if profile != "" {
+		values, ok := profiles[profile].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("profile %s is not defined", profile)
+		}
+		mergeLayer(merged, values, "", sources, "profile "+profile, false)
+		merged["profile"] = profile
+	}
+
+	data, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	config.Sources = sources
+	config.Warnings = warnings
+	if len(narrow) > 0 {
+		config.Policy.narrow = append(config.Policy.narrow, narrow)
+	}
+	if len(profiles) > 0 {
+		config.Profiles = map[string]map[string]interface{}{}
+		for name, values := range profiles {
+			if m, ok := values.(map[string]interface{}); ok {
+				config.Profiles[name] = m
+			}
+		}
+	}
+
+	return &config, nil
+}
+
+// readLayer reads config file migrated to the current version, old
+// format and unknown keys are reported as warnings
+func readLayer(path, source string) (*layer, []string, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+
+	file, err := OpenFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes, err := file.Migrate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	data, err := file.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	var warnings []string
+	if len(changes) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s uses an old config format, run 'g8t config migrate' to update it", path))
+	}
+	return &layer{source: source, values: values}, warnings, nil
+}
+
+// projectAllow removes policy.allow from project values and returns its
+// patterns. A project can narrow the commands the user allows but not
+// replace them, so its list applies on top of the others
+func projectAllow(values map[string]interface{}) []string {
+	policy, ok := values["policy"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	allow, ok := policy["allow"].([]interface{})
+	if !ok {
+		return nil
+	}
+	delete(policy, "allow")
+	patterns := make([]string, len(allow))
+	for i, pattern := range allow {
+		patterns[i] = fmt.Sprint(pattern)
+	}
+	return patterns
+}
+
+func unknownKeyWarnings(l *layer) []string {
+	var warnings []string
+	for _, problem := range UnknownKeys(l.values) {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", l.source, problem))
+	}
+	return warnings
+}
+
+// mergeLayer deep merges src into dst and records source of every leaf,
+// strict keeps the strictest value of strictKeys
+func mergeLayer(dst, src map[string]interface{}, prefix string, sources map[string]string, source string, strict bool) {
+	for key, value := range src {
+		path := prefix + key
+
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			dstMap, ok := dst[key].(map[string]interface{})
+			if !ok {
+				dstMap = map[string]interface{}{}
+				dst[key] = dstMap
+			}
+			mergeLayer(dstMap, srcMap, path+".", sources, source, strict)
+			continue
+		}
+
+		if appendKeys[path] && dst[key] != nil {
+			dst[key] = appendValue(dst[key], value)
+			sources[path] += ", " + source
+			continue
+		}
+
+		if strict && strictKeys[path] && dst[key] != nil && !stricter(value, dst[key]) {
+			continue
+		}
+
+		dst[key] = value
+		sources[path] = source
+	}
+}
+
+// stricter reports whether value is stricter than existing, dry run
+// over a real one and a lower risk level over a higher one. Invalid
+// values win so validation reports them
+func stricter(value, existing interface{}) bool {
+	if on, ok := value.(bool); ok {
+		return on
+	}
+	level, err := risk.ParseLevel(fmt.Sprint(value))
+	if err != nil {
+		return true
+	}
+	current, err := risk.ParseLevel(fmt.Sprint(existing))
+	return err != nil || level < current
+}
+
+func appendValue(existing, value interface{}) interface{} {
+	if list, ok := existing.([]interface{}); ok {
+		if more, ok := value.([]interface{}); ok {
+			return append(list, more...)
+		}
+		return append(list, value)
+	}
+	return strings.TrimSpace(fmt.Sprint(existing)) + "\n\n" + strings.TrimSpace(fmt.Sprint(value))
+}
+
+// Source returns where value of dotted key came from
+func (c *Config) Source(key string) string {
+	if source, ok := c.Sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+// Flatten returns config values as dotted keys in sorted order
+func (c *Config) Flatten() ([]string, map[string]string, error) {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	values := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &values); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	delete(values, "profiles")
+
+	flat := map[string]string{}
+	flatten(values, "", flat)
+
+	keys := make([]string, 0, len(flat))
+	for key := range flat {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, flat, nil
+}
+
+func flatten(values map[string]interface{}, prefix string, flat map[string]string) {
+	for key, value := range values {
+		if m, ok := value.(map[string]interface{}); ok {
+			flatten(m, prefix+key+".", flat)
+			continue
+		}
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			flat[prefix+key] = "[" + strings.Join(items, ", ") + "]"
+			continue
+		}
+		flat[prefix+key] = fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	root := t.TempDir()
+	userPath := filepath.Join(root, "home", ".g8t.yml")
+	writeFile(t, userPath, `provider: claude
+openai_key: sk-legacy
+system_prompt: user rules
+dry_run: true
+policy:
+  deny: ["rm -rf /"]
+  allow: ["^git ", "^ls"]
+  max_risk: high
+  approve_risk: medium
+providers:
+  claude:
+    key: ck
+profiles:
+  local:
+    provider: ollama
+    max_commands: 5
+    providers:
+      ollama:
+        model: llama3
+  unattended:
+    dry_run: false
+`)
+	writeFile(t, filepath.Join(root, "project", ".g8t.yml"), `provider: mock
+profile: local
+dry_run: false
+system_prompt: project rules
+command_timeout: 2m
+policy:
+  deny: ["git push"]
+  allow: ["^git status", "^rm"]
+  max_risk: medium
+  approve_risk: high
+providers:
+  openai:
+    url: http://example.com
+`)
+	dir := filepath.Join(root, "project", "src", "pkg")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := load(userPath, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Provider != "mock" || config.Source("provider") != filepath.Join(root, "project", ".g8t.yml") {
+		t.Errorf("expected project provider, got %s from %s", config.Provider, config.Source("provider"))
+	}
+	if config.SystemPrompt != "user rules\n\nproject rules" {
+		t.Errorf("expected system prompts to add up, got %q", config.SystemPrompt)
+	}
+	if !reflect.DeepEqual(config.Policy.Deny, []string{"rm -rf /", "git push"}) {
+		t.Errorf("expected deny patterns to add up, got %q", config.Policy.Deny)
+	}
+	if config.Policy.MaxRisk != "medium" || config.Policy.ApproveRisk != "medium" {
+		t.Errorf("expected the strictest risk levels, got %s and %s", config.Policy.MaxRisk, config.Policy.ApproveRisk)
+	}
+	if config.CommandTimeout != "2m" || config.MaxCommands != 20 || config.Source("max_commands") != SourceDefault {
+		t.Errorf("unexpected timeout %q and max commands %d", config.CommandTimeout, config.MaxCommands)
+	}
+	if !config.DryRun || config.Source("dry_run") != userPath {
+		t.Errorf("expected project not to turn dry run off")
+	}
+	if config.Policy.Check("git status") != nil || config.Policy.Check("git log") == nil || config.Policy.Check("rm -rf build") == nil {
+		t.Errorf("expected project allow list to narrow the user one, got %q", config.Policy.Allow)
+	}
+	if config.Profile != "" || config.ProviderSettings("openai")["url"] != "" || len(config.Warnings) != 3 ||
+		!strings.Contains(config.Warnings[0], "config migrate") || !strings.Contains(strings.Join(config.Warnings[1:], " "), "key providers is only allowed") ||
+		!strings.Contains(strings.Join(config.Warnings[1:], " "), "key profile is only allowed") {
+		t.Errorf("expected migration hint and ignored project settings warnings, got %q", config.Warnings)
+	}
+	if config.ProviderSettings("openai")["key"] != "sk-legacy" || config.Source("providers.openai.key") != userPath {
+		t.Errorf("expected legacy key to be moved with its source")
+	}
+
+	config, err = load(userPath, dir, "local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Provider != "ollama" || config.MaxCommands != 5 || config.ProviderSettings("ollama")["model"] != "llama3" {
+		t.Errorf("expected profile to win over project, got %s %d", config.Provider, config.MaxCommands)
+	}
+	if config.Source("provider") != "profile local" {
+		t.Errorf("unexpected provider source %s", config.Source("provider"))
+	}
+
+	config, err = load(userPath, dir, "unattended")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.DryRun || config.Source("dry_run") != "profile unattended" {
+		t.Errorf("expected profile to turn dry run off, got %v from %s", config.DryRun, config.Source("dry_run"))
+	}
+
+	if _, err := load(userPath, dir, "missing"); err == nil {
+		t.Error("expected error for undefined profile")
+	}
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/d1nch8g/g8t/gpt"
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the current config format version. Version 1 files have
+// no version key and keep provider settings in flat keys like openai_key
+const Version = 2
+
+// FileVersion returns format version of file, 1 when it is not set
+func (f *File) FileVersion() (int, error) {
+	value, ok := f.Get("version")
+	if !ok {
+		return 1, nil
+	}
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid config version %q", value)
+	}
+	return version, nil
+}
+
+// Migrate upgrades file to the current version and describes the
+// changes made, versionless files that need no changes are only stamped
+func (f *File) Migrate() ([]string, error) {
+	version, err := f.FileVersion()
+	if err != nil {
+		return nil, err
+	}
+	if version > Version {
+		return nil, fmt.Errorf("config version %d is newer than supported version %d, upgrade g8t", version, Version)
+	}
+	if version == Version {
+		return nil, nil
+	}
+
+	changes := f.migrateV1()
+	f.setVersion()
+	return changes, nil
+}
+
+// migrateV1 moves flat provider keys under providers and drops dummy
+// values older setup wizards wrote for every provider
+func (f *File) migrateV1() []string {
+	var changes []string
+	for _, provider := range gpt.Providers() {
+		for _, field := range provider.Fields {
+			key := "providers." + provider.Name + "." + field.Name
+
+			if value, ok := f.Get(field.LegacyKey); ok && field.LegacyKey != "" {
+				f.Unset(field.LegacyKey)
+				if field.Placeholder && value == field.Default {
+					changes = append(changes, fmt.Sprintf("removed placeholder value of %s", field.LegacyKey))
+				} else {
+					if current, ok := f.Get(key); !ok || current == "" {
+						f.Set(key, value)
+					}
+					changes = append(changes, fmt.Sprintf("moved %s to %s", field.LegacyKey, key))
+				}
+			}
+
+			if value, ok := f.Get(key); ok && field.Placeholder && value == field.Default {
+				f.Unset(key)
+				changes = append(changes, fmt.Sprintf("removed placeholder value of %s", key))
+			}
+		}
+	}
+	return changes
+}
+
+// setVersion puts version key first so it is the first thing readers see
+func (f *File) setVersion() {
+	f.Unset("version")
+	f.root.Content = append([]*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "version"},
+		{Kind: yaml.ScalarNode, Value: strconv.Itoa(Version), Tag: "!!int"},
+	}, f.root.Content...)
+}
+
+// UnknownKeys describes keys of values that no config option matches
+func UnknownKeys(values map[string]interface{}) []string {
+	flat := map[string]bool{}
+	leaves(values, "", flat)
+
+	var problems []string
+	for key := range flat {
+		if key == "version" {
+			continue
+		}
+		if _, err := LookupKey(key); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+func leaves(values map[string]interface{}, prefix string, flat map[string]bool) {
+	for key, value := range values {
+		if m, ok := value.(map[string]interface{}); ok {
+			leaves(m, prefix+key+".", flat)
+			continue
+		}
+		flat[prefix+key] = true
+	}
+}
+
+// Diff returns line based diff of a and b with unchanged lines
+// prefixed by two spaces and changed ones by "- " and "+ "
+func Diff(a, b string) string {
+	x := strings.Split(strings.TrimRight(a, "\n"), "\n")
+	y := strings.Split(strings.TrimRight(b, "\n"), "\n")
+
+	// lcs[i][j] is length of the longest common subsequence of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			out.WriteString("  " + x[i] + "\n")
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			out.WriteString("+ " + y[j] + "\n")
+			j++
+		default:
+			out.WriteString("- " + x[i] + "\n")
+			i++
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMigrateV1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".g8t.yml")
+	writeFile(t, path, `provider: claude
+openai_key: your-openai-key
+claude_key: sk-ant
+claude_model: claude-3-opus
+providers:
+  gemini:
+    key: your-gemini-key
+max_commands: 20
+`)
+
+	file, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := file.Migrate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"moved claude_key to providers.claude.key",
+		"moved claude_model to providers.claude.model",
+		"removed placeholder value of providers.gemini.key",
+		"removed placeholder value of openai_key",
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Fatalf("changes = %q", changes)
+	}
+
+	data, err := file.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `version: 2
+provider: claude
+providers:
+  claude:
+    key: sk-ant
+    model: claude-3-opus
+max_commands: 20
+` {
+		t.Fatalf("unexpected migrated file:\n%s", data)
+	}
+
+	if changes, err := file.Migrate(); err != nil || changes != nil {
+		t.Fatalf("expected migrated file to stay unchanged, got %q, %v", changes, err)
+	}
+
+	if err := file.Set("version", 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Migrate(); err == nil {
+		t.Fatal("expected error for newer version")
+	}
+}
+
+func TestUnknownKeys(t *testing.T) {
+	problems := UnknownKeys(map[string]interface{}{
+		"version":  2,
+		"colour":   true,
+		"provider": "openai",
+		"providers": map[string]interface{}{
+			"openai": map[string]interface{}{"key": "k", "organisation": "o"},
+		},
+		"profiles": map[string]interface{}{
+			"work": map[string]interface{}{"max_commands": 5, "retries": 3},
+		},
+	})
+	want := []string{
+		"openai provider has no setting organisation",
+		"unknown config key colour",
+		"unknown config key retries",
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Fatalf("problems = %q", problems)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	if _, err := json.Marshal(Schema()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigFieldsAreKnownKeys(t *testing.T) {
+	schema := Schema()
+	var check func(typ reflect.Type, prefix string)
+	check = func(typ reflect.Type, prefix string) {
+		for i := 0; i < typ.NumField(); i++ {
+			name := strings.Split(typ.Field(i).Tag.Get("yaml"), ",")[0]
+			switch name {
+			case "", "-", "version", "providers", "profiles", "generation":
+				// Versions, providers and generation options are checked
+				// on their own
+				continue
+			}
+			path := prefix + name
+			if typ.Field(i).Type.Kind() == reflect.Struct {
+				check(typ.Field(i).Type, path+".")
+				continue
+			}
+			if _, err := LookupKey(path); err != nil {
+				t.Errorf("field %s of %s: %v", typ.Field(i).Name, typ.Name(), err)
+			}
+			property := schema
+			for _, part := range strings.Split(path, ".") {
+				property, _ = property["properties"].(map[string]interface{})[part].(map[string]interface{})
+			}
+			if property == nil {
+				t.Errorf("field %s of %s is missing in schema", typ.Field(i).Name, typ.Name())
+			}
+		}
+	}
+	check(reflect.TypeOf(Config{}), "")
+}
+
+func TestDiff(t *testing.T) {
+	got := Diff("a\nb\nc\n", "a\nc\nd\n")
+	if got != "  a\n- b\n  c\n+ d\n" {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
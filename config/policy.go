@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/d1nch8g/g8t/risk"
+)
+
+// Policy restricts commands the agent may run. Patterns are regular
+// expressions matched against the whole command line, a command matching
+// any Deny pattern is refused and, when Allow is not empty, a command has
+// to match one of Allow patterns
+type Policy struct {
+	Deny  []string `yaml:"deny,omitempty"`
+	Allow []string `yaml:"allow,omitempty"`
+	// MaxRisk refuses commands classified above this risk level
+	MaxRisk string `yaml:"max_risk,omitempty"`
+	// ApproveRisk makes commands of this risk level and above wait for
+	// the user to approve them
+	ApproveRisk string `yaml:"approve_risk,omitempty"`
+
+	// narrow holds allow lists of project configs, a command has to match
+	// one pattern of each of them as well
+	narrow [][]string
+}
+
+// Validate checks that all patterns compile and risk levels are known
+func (p Policy) Validate() error {
+	patterns := append(append([]string{}, p.Deny...), p.Allow...)
+	for _, allow := range p.narrow {
+		patterns = append(patterns, allow...)
+	}
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	for _, level := range []string{p.MaxRisk, p.ApproveRisk} {
+		if level == "" {
+			continue
+		}
+		if _, err := risk.ParseLevel(level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckRisk returns an error when assessed command is riskier than
+// MaxRisk allows
+func (p Policy) CheckRisk(a risk.Assessment) error {
+	if p.MaxRisk == "" {
+		return nil
+	}
+	max, _ := risk.ParseLevel(p.MaxRisk)
+	if a.Level > max {
+		return fmt.Errorf("command is %s risk, policy allows up to %s", a.Level, max)
+	}
+	return nil
+}
+
+// NeedsApproval reports whether assessed command has to be approved by
+// the user before it runs
+func (p Policy) NeedsApproval(a risk.Assessment) bool {
+	if p.ApproveRisk == "" {
+		return false
+	}
+	level, _ := risk.ParseLevel(p.ApproveRisk)
+	return a.Level >= level
+}
+
+// Check returns an error describing why command is not allowed
+func (p Policy) Check(command string) error {
+	for _, pattern := range p.Deny {
+		if matched, _ := regexp.MatchString(pattern, command); matched {
+			return fmt.Errorf("command matches denied pattern %q", pattern)
+		}
+	}
+	for _, allow := range append([][]string{p.Allow}, p.narrow...) {
+		if len(allow) > 0 && !matchesAny(allow, command) {
+			return fmt.Errorf("command does not match any allowed pattern")
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, command string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := regexp.MatchString(pattern, command); matched {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/d1nch8g/g8t/gpt"
+)
+
+// SchemaID identifies the JSON Schema of config files
+const SchemaID = "https://github.com/d1nch8g/g8t/config.schema.json"
+
+// Schema returns JSON Schema of config files derived from known keys
+// and registered providers, so custom providers are covered as well
+func Schema() map[string]interface{} {
+	general := object()
+	for key, kind := range generalKeys {
+		setProperty(general, strings.Split(key, "."), kindSchema(kind))
+	}
+
+	generation := object()
+	for key, kind := range generationKeys {
+		setProperty(generation, []string{key}, kindSchema(kind))
+	}
+	setProperty(general, []string{"generation"}, generation)
+
+	providers := object()
+	for _, provider := range gpt.Providers() {
+		if provider.Hidden {
+			continue
+		}
+		settings := object()
+		settings["description"] = provider.Description
+		for _, field := range provider.Fields {
+			property := map[string]interface{}{"type": "string", "description": field.Prompt}
+			if field.Default != "" && !field.Placeholder {
+				property["default"] = field.Default
+			}
+			setProperty(settings, []string{field.Name}, property)
+		}
+		setProperty(settings, []string{"generation"}, generation)
+		setProperty(providers, []string{provider.Name}, settings)
+	}
+	setProperty(general, []string{"providers"}, providers)
+
+	// Profiles may set anything the top level does except profiles
+	profile := copySchema(general)
+	schema := copySchema(general)
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["$id"] = SchemaID
+	schema["title"] = "g8t configuration"
+	setProperty(schema, []string{"version"}, map[string]interface{}{"type": "integer", "const": Version})
+	setProperty(schema, []string{"profiles"}, map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": profile,
+	})
+	return schema
+}
+
+func object() map[string]interface{} {
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           map[string]interface{}{},
+		"additionalProperties": false,
+	}
+}
+
+// setProperty sets schema of dotted path creating intermediate objects
+func setProperty(schema map[string]interface{}, path []string, property map[string]interface{}) {
+	properties := schema["properties"].(map[string]interface{})
+	if len(path) == 1 {
+		properties[path[0]] = property
+		return
+	}
+	child, ok := properties[path[0]].(map[string]interface{})
+	if !ok {
+		child = object()
+		properties[path[0]] = child
+	}
+	setProperty(child, path[1:], property)
+}
+
+func copySchema(schema map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for k, v := range schema {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = copySchema(m)
+		}
+		result[k] = v
+	}
+	return result
+}
+
+func kindSchema(kind Kind) map[string]interface{} {
+	switch kind {
+	case KindInt:
+		return map[string]interface{}{"type": "integer"}
+	case KindFloat:
+		return map[string]interface{}{"type": "number"}
+	case KindBool:
+		return map[string]interface{}{"type": "boolean"}
+	case KindDuration:
+		return map[string]interface{}{"type": "string", "pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`}
+	case KindList, KindPatterns:
+		return map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}}
+	case KindProvider:
+		return map[string]interface{}{"type": "string", "enum": gpt.Names()}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
@@ -0,0 +1,161 @@
+package eval
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/gpt"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunSuite(t *testing.T) {
+	dir := t.TempDir()
+	good := writeFile(t, dir, "good.yml", `responses:
+  - thought: write greeting
+    command: cat seed.txt > hello.txt && echo world >> hello.txt
+  - thought: done
+    command: TASK_COMPLETE
+    input_tokens: 100
+    output_tokens: 20
+`)
+	bad := writeFile(t, dir, "bad.yml", `responses:
+  - thought: wrong file
+    command: echo nope > other.txt
+  - thought: done
+    command: TASK_COMPLETE
+`)
+	suitePath := writeFile(t, dir, "suite.yml", `name: greetings
+runs: 2
+max_commands: 4
+matrix:
+  - name: good
+    provider: mock
+    settings:
+      script: `+good+`
+  - provider: mock
+    settings:
+      script: `+bad+`
+tasks:
+  - name: hello
+    task: write hello world to hello.txt
+    setup:
+      - echo hello > seed.txt
+    checks:
+      - file_exists: hello.txt
+      - file: hello.txt
+        matches: "(?m)^world$"
+      - command: grep -q hello hello.txt
+`)
+
+	suite, err := LoadSuite(suitePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var progress []Result
+	runner := &Runner{Config: &config.Config{}, Progress: func(r Result) { progress = append(progress, r) }}
+	report := runner.Run(suite)
+
+	if len(progress) != 4 || len(report.Results) != 4 {
+		t.Fatalf("expected 4 runs, got %d", len(report.Results))
+	}
+
+	goodCell := report.Cell("hello", "good")
+	if goodCell.Passed != 2 || goodCell.PassRate != 1 || goodCell.AvgSteps != 2 || goodCell.AvgTokens != 120 {
+		t.Fatalf("unexpected good cell %+v", goodCell)
+	}
+	badCell := report.Cell("hello", "mock")
+	if badCell.Passed != 0 || badCell.Runs != 2 {
+		t.Fatalf("unexpected bad cell %+v", badCell)
+	}
+	if !strings.Contains(report.Results[2].Error, "file exists: hello.txt") {
+		t.Fatalf("failed check not reported: %q", report.Results[2].Error)
+	}
+
+	var table bytes.Buffer
+	if err := report.WriteTable(&table); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "good") || !strings.Contains(table.String(), "TOTAL") {
+		t.Fatalf("unexpected table:\n%s", table.String())
+	}
+
+	var decoded Report
+	var data bytes.Buffer
+	if err := report.WriteJSON(&data); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.Totals) != 2 || decoded.Totals[0].PassRate != 1 || decoded.Totals[1].PassRate != 0 {
+		t.Fatalf("unexpected totals %+v", decoded.Totals)
+	}
+}
+
+func TestTargetConfigDoesNotModifyUserConfig(t *testing.T) {
+	cfg := &config.Config{
+		Provider: "openai",
+		Providers: map[string]*config.ProviderConfig{
+			"openai": {Settings: gpt.Settings{"key": "sk", "model": "gpt-4o"}},
+		},
+	}
+	runner := &Runner{Config: cfg}
+
+	derived := runner.targetConfig(Target{Provider: "openai", Model: "o3-mini"}, 3, "/tmp")
+	if derived.ProviderSettings("openai")["model"] != "o3-mini" || derived.ProviderSettings("openai")["key"] != "sk" {
+		t.Fatalf("unexpected derived settings %v", derived.ProviderSettings("openai"))
+	}
+	if cfg.Providers["openai"].Settings["model"] != "gpt-4o" {
+		t.Fatalf("user config modified")
+	}
+}
+
+func TestLoadSuiteValidation(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"no matrix":        "tasks: [{name: a, task: b, checks: [{file_exists: x}]}]",
+		"unknown provider": "matrix: [{provider: nope}]\ntasks: [{name: a, task: b, checks: [{file_exists: x}]}]",
+		"no checks":        "matrix: [{provider: mock}]\ntasks: [{name: a, task: b}]",
+		"ambiguous check":  "matrix: [{provider: mock}]\ntasks: [{name: a, task: b, checks: [{file_exists: x, command: ls}]}]",
+		"bad pattern":      "matrix: [{provider: mock}]\ntasks: [{name: a, task: b, checks: [{file: x, matches: '('}]}]",
+		"duplicate target": "matrix: [{name: x, provider: mock}, {name: x, provider: mock, model: m}]\ntasks: [{name: a, task: b, checks: [{file_exists: x}]}]",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := writeFile(t, dir, strings.ReplaceAll(name, " ", "_")+".yml", content)
+			if _, err := LoadSuite(path); err == nil {
+				t.Fatalf("expected validation error")
+			}
+		})
+	}
+}
+
+func TestTargetName(t *testing.T) {
+	suite := &Suite{Matrix: []Target{
+		{Provider: "openai", Model: "gpt-4o"},
+		{Provider: "openai", Model: "gpt-4o", Generation: gpt.Options{MaxTokens: 100}},
+		{Provider: "claude"},
+		{Name: "cold", Provider: "openai", Model: "gpt-4o"},
+	}}
+	var names []string
+	for i := range suite.Matrix {
+		names = append(names, suite.TargetName(i))
+	}
+	if strings.Join(names, ", ") != "openai/gpt-4o #1, openai/gpt-4o #2, claude, cold" {
+		t.Fatalf("unexpected names %q", names)
+	}
+}
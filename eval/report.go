@@ -0,0 +1,145 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Result is the outcome of a single run of a task on a target
+type Result struct {
+	Task     string        `json:"task"`
+	Target   string        `json:"target"`
+	Run      int           `json:"run"`
+	Passed   bool          `json:"passed"`
+	Steps    int           `json:"steps"`
+	Tokens   int           `json:"tokens"`
+	Duration time.Duration `json:"-"`
+	Seconds  float64       `json:"seconds"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// Cell aggregates all runs of a task on a target, Task is empty
+// for per target totals
+type Cell struct {
+	Task       string  `json:"task,omitempty"`
+	Target     string  `json:"target"`
+	Runs       int     `json:"runs"`
+	Passed     int     `json:"passed"`
+	PassRate   float64 `json:"pass_rate"`
+	AvgSteps   float64 `json:"avg_steps"`
+	AvgTokens  float64 `json:"avg_tokens"`
+	AvgSeconds float64 `json:"avg_seconds"`
+}
+
+// Report holds results of a suite run
+type Report struct {
+	Suite   string   `json:"suite"`
+	Targets []string `json:"targets"`
+	Tasks   []string `json:"tasks"`
+	Cells   []Cell   `json:"cells"`
+	Totals  []Cell   `json:"totals"`
+	Results []Result `json:"results"`
+}
+
+// NewReport aggregates results into cells
+func NewReport(suite *Suite, results []Result) *Report {
+	report := &Report{Suite: suite.Name, Results: results}
+	for i := range suite.Matrix {
+		report.Targets = append(report.Targets, suite.TargetName(i))
+	}
+	for _, task := range suite.Tasks {
+		report.Tasks = append(report.Tasks, task.Name)
+	}
+
+	for _, task := range report.Tasks {
+		for _, target := range report.Targets {
+			report.Cells = append(report.Cells, aggregate(task, target, results))
+		}
+	}
+	for _, target := range report.Targets {
+		report.Totals = append(report.Totals, aggregate("", target, results))
+	}
+	return report
+}
+
+func aggregate(task, target string, results []Result) Cell {
+	cell := Cell{Task: task, Target: target}
+	var steps, tokens int
+	var seconds float64
+	for _, result := range results {
+		if result.Target != target || (task != "" && result.Task != task) {
+			continue
+		}
+		cell.Runs++
+		if result.Passed {
+			cell.Passed++
+		}
+		steps += result.Steps
+		tokens += result.Tokens
+		seconds += result.Seconds
+	}
+	if cell.Runs > 0 {
+		runs := float64(cell.Runs)
+		cell.PassRate = float64(cell.Passed) / runs
+		cell.AvgSteps = float64(steps) / runs
+		cell.AvgTokens = float64(tokens) / runs
+		cell.AvgSeconds = seconds / runs
+	}
+	return cell
+}
+
+// Cell returns aggregated cell of task and target
+func (r *Report) Cell(task, target string) Cell {
+	for _, cell := range r.Cells {
+		if cell.Task == task && cell.Target == target {
+			return cell
+		}
+	}
+	return Cell{Task: task, Target: target}
+}
+
+// WriteTable prints tasks as rows and targets as columns
+func (r *Report) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+
+	fmt.Fprintf(tw, "TASK\t%s\n", strings.Join(r.Targets, "\t"))
+	for _, task := range r.Tasks {
+		row := []string{task}
+		for _, target := range r.Targets {
+			row = append(row, r.Cell(task, target).String())
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	row := []string{"TOTAL"}
+	for _, total := range r.Totals {
+		row = append(row, total.String())
+	}
+	fmt.Fprintln(tw, strings.Join(row, "\t"))
+
+	return tw.Flush()
+}
+
+// WriteJSON writes report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// String formats cell as pass rate, steps, tokens and time
+func (c Cell) String() string {
+	return fmt.Sprintf("%d/%d %3.0f%% %.1f steps %s tok %.1fs",
+		c.Passed, c.Runs, c.PassRate*100, c.AvgSteps, formatTokens(c.AvgTokens), c.AvgSeconds)
+}
+
+func formatTokens(tokens float64) string {
+	if tokens >= 1000 {
+		return fmt.Sprintf("%.1fk", tokens/1000)
+	}
+	return fmt.Sprintf("%.0f", tokens)
+}
@@ -0,0 +1,194 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/d1nch8g/g8t/agent"
+	"github.com/d1nch8g/g8t/config"
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+// shellTimeout limits setup commands and command checks
+const shellTimeout = time.Minute
+
+// Runner executes suites
+type Runner struct {
+	// Config provides credentials and defaults for every target
+	Config *config.Config
+	// Log receives agent output, discarded when nil
+	Log     io.Writer
+	Verbose bool
+	// Progress is called after every run, optional
+	Progress func(Result)
+}
+
+// Run evaluates every task against every target the configured number
+// of times, tasks run one by one to keep timings comparable
+func (r *Runner) Run(suite *Suite) *Report {
+	var results []Result
+	for _, task := range suite.Tasks {
+		for i, target := range suite.Matrix {
+			for run := 1; run <= suite.Runs; run++ {
+				result := r.runTask(suite, task, target, suite.TargetName(i))
+				result.Run = run
+				results = append(results, result)
+				if r.Progress != nil {
+					r.Progress(result)
+				}
+			}
+		}
+	}
+	return NewReport(suite, results)
+}
+
+func (r *Runner) runTask(suite *Suite, task Task, target Target, name string) Result {
+	result := Result{Task: task.Name, Target: name}
+
+	dir, err := os.MkdirTemp("", "g8t-eval-")
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to create work directory: %v", err)
+		return result
+	}
+	defer os.RemoveAll(dir)
+
+	for _, command := range task.Setup {
+		if output, err := runShell(dir, command); err != nil {
+			result.Error = fmt.Sprintf("setup command %q failed: %v: %s", command, err, output)
+			return result
+		}
+	}
+
+	maxCommands := task.MaxCommands
+	if maxCommands == 0 {
+		maxCommands = suite.MaxCommands
+	}
+	cfg := r.targetConfig(target, maxCommands, dir)
+
+	out := r.Log
+	if out == nil {
+		out = io.Discard
+	}
+	fmt.Fprintf(out, "\n=== %s on %s\n", task.Name, name)
+
+	a, err := agent.New(cfg, logger.NewWithWriter(r.Verbose, false, out))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	runErr := a.Run(task.Task)
+	stats := a.Stats()
+	result.Steps = stats.Steps
+	result.Tokens = stats.Usage.TotalTokens
+	result.Duration = stats.Duration
+	result.Seconds = stats.Duration.Seconds()
+
+	if runErr != nil {
+		result.Error = runErr.Error()
+		return result
+	}
+
+	for _, check := range task.Checks {
+		if err := check.run(dir); err != nil {
+			result.Error = fmt.Sprintf("check failed: %s: %v", check, err)
+			return result
+		}
+	}
+
+	result.Passed = true
+	return result
+}
+
+// targetConfig derives config of a single run from the user config,
+// target settings take precedence over configured ones
+func (r *Runner) targetConfig(target Target, maxCommands int, dir string) *config.Config {
+	cfg := *r.Config
+	cfg.Provider = target.Provider
+	cfg.Task = ""
+	cfg.Attachments = nil
+	cfg.DryRun = false
+	// Cached answers would make repeated runs meaningless
+	cfg.NoCache = true
+	cfg.WorkDir = dir
+	if maxCommands > 0 {
+		cfg.MaxCommands = maxCommands
+	}
+	if cfg.MaxCommands <= 0 {
+		cfg.MaxCommands = 20
+	}
+
+	settings := gpt.Settings{}
+	var generation gpt.Options
+	if pc := r.Config.Providers[target.Provider]; pc != nil {
+		for k, v := range pc.Settings {
+			settings[k] = v
+		}
+		generation = pc.Generation
+	}
+	for k, v := range target.Settings {
+		settings[k] = v
+	}
+	if target.Model != "" {
+		settings["model"] = target.Model
+	}
+
+	cfg.Providers = map[string]*config.ProviderConfig{}
+	for name, pc := range r.Config.Providers {
+		cfg.Providers[name] = pc
+	}
+	cfg.Providers[target.Provider] = &config.ProviderConfig{
+		Settings:   settings,
+		Generation: generation.Merge(target.Generation),
+	}
+
+	return &cfg
+}
+
+func (c Check) run(dir string) error {
+	switch {
+	case c.FileExists != "":
+		if _, err := os.Stat(filepath.Join(dir, c.FileExists)); err != nil {
+			return fmt.Errorf("file does not exist")
+		}
+	case c.Command != "":
+		output, err := runShell(dir, c.Command)
+		code := 0
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			return err
+		}
+		if code != c.ExitCode {
+			return fmt.Errorf("exit code %d: %s", code, output)
+		}
+	default:
+		data, err := os.ReadFile(filepath.Join(dir, c.File))
+		if err != nil {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+		if !regexp.MustCompile(c.Matches).Match(data) {
+			return fmt.Errorf("content does not match")
+		}
+	}
+	return nil
+}
+
+func runShell(dir, command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "bash", "-c", command)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	return string(output), err
+}
@@ -0,0 +1,181 @@
+package eval
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/d1nch8g/g8t/gpt"
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a set of tasks evaluated against a matrix of providers and models
+type Suite struct {
+	Name        string   `yaml:"name"`
+	Matrix      []Target `yaml:"matrix"`
+	Tasks       []Task   `yaml:"tasks"`
+	Runs        int      `yaml:"runs"`
+	MaxCommands int      `yaml:"max_commands"`
+}
+
+// Target is a provider and model combination under evaluation, Name
+// tells apart entries that differ in settings or generation only
+type Target struct {
+	Name       string       `yaml:"name"`
+	Provider   string       `yaml:"provider"`
+	Model      string       `yaml:"model"`
+	Settings   gpt.Settings `yaml:"settings"`
+	Generation gpt.Options  `yaml:"generation"`
+}
+
+// Task is a single task run in a fresh temporary directory
+type Task struct {
+	Name        string   `yaml:"name"`
+	Task        string   `yaml:"task"`
+	Setup       []string `yaml:"setup"`
+	Checks      []Check  `yaml:"checks"`
+	MaxCommands int      `yaml:"max_commands"`
+}
+
+// Check verifies outcome of a task, exactly one kind of check must be set:
+// FileExists, Command with expected ExitCode, or File content Matches
+type Check struct {
+	FileExists string `yaml:"file_exists"`
+	Command    string `yaml:"command"`
+	ExitCode   int    `yaml:"exit_code"`
+	File       string `yaml:"file"`
+	Matches    string `yaml:"matches"`
+}
+
+// LoadSuite reads and validates suite file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+
+	var suite Suite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite %s: %w", path, err)
+	}
+	if suite.Name == "" {
+		suite.Name = path
+	}
+	if suite.Runs == 0 {
+		suite.Runs = 1
+	}
+
+	if err := suite.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid suite %s: %w", path, err)
+	}
+	return &suite, nil
+}
+
+// Validate checks that suite is complete
+func (s *Suite) Validate() error {
+	if len(s.Matrix) == 0 {
+		return fmt.Errorf("matrix must list at least one provider")
+	}
+	if len(s.Tasks) == 0 {
+		return fmt.Errorf("at least one task is required")
+	}
+	if s.Runs < 1 {
+		return fmt.Errorf("runs must be greater than 0")
+	}
+
+	targets := map[string]bool{}
+	for i, target := range s.Matrix {
+		if _, ok := gpt.Lookup(target.Provider); !ok {
+			return fmt.Errorf("unsupported provider: %s", target.Provider)
+		}
+		name := s.TargetName(i)
+		if targets[name] {
+			return fmt.Errorf("duplicate target name %s", name)
+		}
+		targets[name] = true
+	}
+
+	names := map[string]bool{}
+	for i, task := range s.Tasks {
+		if task.Name == "" {
+			return fmt.Errorf("task %d has no name", i+1)
+		}
+		if names[task.Name] {
+			return fmt.Errorf("duplicate task name %s", task.Name)
+		}
+		names[task.Name] = true
+
+		if task.Task == "" {
+			return fmt.Errorf("task %s has no description", task.Name)
+		}
+		if len(task.Checks) == 0 {
+			return fmt.Errorf("task %s has no checks", task.Name)
+		}
+		for _, check := range task.Checks {
+			if err := check.validate(); err != nil {
+				return fmt.Errorf("task %s: %w", task.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// TargetName returns name results of i-th matrix entry are reported
+// under. Entries without a name use provider/model, followed by their
+// position in the matrix when another entry has the same one
+func (s *Suite) TargetName(i int) string {
+	target := s.Matrix[i]
+	if target.Name != "" {
+		return target.Name
+	}
+	name := target.String()
+	for j, other := range s.Matrix {
+		if j != i && (other.Name == name || other.Name == "" && other.String() == name) {
+			return fmt.Sprintf("%s #%d", name, i+1)
+		}
+	}
+	return name
+}
+
+// String returns target in provider/model form
+func (t Target) String() string {
+	if t.Model == "" {
+		return t.Provider
+	}
+	return t.Provider + "/" + t.Model
+}
+
+func (c Check) validate() error {
+	kinds := 0
+	if c.FileExists != "" {
+		kinds++
+	}
+	if c.Command != "" {
+		kinds++
+	}
+	if c.File != "" {
+		kinds++
+		if c.Matches == "" {
+			return fmt.Errorf("check of file %s needs matches pattern", c.File)
+		}
+		if _, err := regexp.Compile(c.Matches); err != nil {
+			return fmt.Errorf("invalid matches pattern %q: %w", c.Matches, err)
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("check must have exactly one of file_exists, command or file")
+	}
+	return nil
+}
+
+// String describes check for reports
+func (c Check) String() string {
+	switch {
+	case c.FileExists != "":
+		return "file exists: " + c.FileExists
+	case c.Command != "":
+		return fmt.Sprintf("command %q exits with %d", c.Command, c.ExitCode)
+	default:
+		return fmt.Sprintf("file %s matches %q", c.File, c.Matches)
+	}
+}
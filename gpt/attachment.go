@@ -0,0 +1,98 @@
+package gpt
+
+import (
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxAttachmentSize limits size of a single attached file
+const MaxAttachmentSize = 20 << 20
+
+// Attachment is a file sent to the model along with the task
+type Attachment struct {
+	Name     string
+	MIMEType string
+	Data     []byte
+}
+
+// LoadAttachment reads file from disk and detects whether it is an image
+// or a text file, other kinds of files are rejected
+func LoadAttachment(path string) (Attachment, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+	if info.Size() > MaxAttachmentSize {
+		return Attachment{}, fmt.Errorf("attachment %s is larger than %d MB", path, MaxAttachmentSize>>20)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Attachment{}, fmt.Errorf("failed to read attachment: %w", err)
+	}
+
+	attachment := Attachment{
+		Name:     filepath.Base(path),
+		MIMEType: http.DetectContentType(data),
+		Data:     data,
+	}
+
+	// Content sniffing can't tell source code from plain text, extension can
+	if byExt := mime.TypeByExtension(filepath.Ext(path)); strings.HasPrefix(byExt, "image/") {
+		attachment.MIMEType = byExt
+	}
+	attachment.MIMEType = strings.TrimSpace(strings.Split(attachment.MIMEType, ";")[0])
+
+	switch {
+	case attachment.IsImage():
+	case utf8.Valid(data):
+		attachment.MIMEType = "text/plain"
+	default:
+		return Attachment{}, fmt.Errorf("attachment %s has unsupported type %s, only images and text files are accepted", path, attachment.MIMEType)
+	}
+
+	return attachment, nil
+}
+
+// IsImage reports whether attachment is an image models can look at
+func (a Attachment) IsImage() bool {
+	switch a.MIMEType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+		return true
+	}
+	return false
+}
+
+// Base64 returns attachment data encoded with standard base64
+func (a Attachment) Base64() string {
+	return base64.StdEncoding.EncodeToString(a.Data)
+}
+
+// DataURL returns attachment as data URL
+func (a Attachment) DataURL() string {
+	return "data:" + a.MIMEType + ";base64," + a.Base64()
+}
+
+// Text returns text attachment wrapped with its file name
+func (a Attachment) Text() string {
+	return fmt.Sprintf("Attached file %s:\n```\n%s\n```", a.Name, string(a.Data))
+}
+
+// inlineAttachments appends text attachments to user message for providers
+// that only accept plain text, images are rejected with an error
+func inlineAttachments(provider string, r Request) (string, error) {
+	user := r.User
+	for _, attachment := range r.Attachments {
+		if attachment.IsImage() {
+			return "", fmt.Errorf("%s provider does not support image attachments, remove %s", provider, attachment.Name)
+		}
+		user += "\n\n" + attachment.Text()
+	}
+	return user, nil
+}
@@ -0,0 +1,277 @@
+package gpt
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cache stores completions on disk, entries are addressed by hash of
+// everything that affects the answer
+type Cache struct {
+	Dir string
+	// TTL is maximum age of an entry, zero keeps entries forever
+	TTL time.Duration
+	// MaxSize is cap of total size in bytes, zero disables the cap
+	MaxSize int64
+}
+
+// CacheStats describes cache content
+type CacheStats struct {
+	Dir     string
+	Entries int
+	Expired int
+	Size    int64
+	Oldest  time.Time
+	Newest  time.Time
+}
+
+type cacheEntry struct {
+	Created  time.Time `json:"created"`
+	Response Response  `json:"response"`
+}
+
+// DefaultCacheDir returns g8t directory inside user cache directory
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	return filepath.Join(dir, "g8t"), nil
+}
+
+// NewCache creates cache stored in dir
+func NewCache(dir string, ttl time.Duration, maxSize int64) *Cache {
+	return &Cache{Dir: dir, TTL: ttl, MaxSize: maxSize}
+}
+
+// Get returns cached response, expired entries are treated as missing
+func (c *Cache) Get(key string) (*Response, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		os.Remove(path)
+		return nil, false
+	}
+	if c.expired(entry.Created) {
+		os.Remove(path)
+		return nil, false
+	}
+
+	// Touch entry so size cap evicts least recently used ones first
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return &entry.Response, true
+}
+
+// Put stores response under key and enforces size cap
+func (c *Cache) Put(key string, response *Response) error {
+	data, err := json.Marshal(cacheEntry{Created: time.Now(), Response: *response})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// Write to temp file first so concurrent readers never see partial entries
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	return c.Prune()
+}
+
+// Prune removes expired entries and evicts least recently used ones
+// until cache fits into MaxSize
+func (c *Cache) Prune() error {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []file
+	var total int64
+	err := c.walk(func(path string, info fs.FileInfo) {
+		if c.TTL > 0 && time.Since(info.ModTime()) > c.TTL && c.expiredFile(path) {
+			os.Remove(path)
+			return
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	})
+	if err != nil {
+		return err
+	}
+
+	if c.MaxSize <= 0 || total <= c.MaxSize {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	for _, f := range files {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := os.Remove(f.path); err == nil {
+			total -= f.size
+		}
+	}
+	return nil
+}
+
+// Stats returns number and size of cached entries
+func (c *Cache) Stats() (CacheStats, error) {
+	stats := CacheStats{Dir: c.Dir}
+	err := c.walk(func(path string, info fs.FileInfo) {
+		stats.Entries++
+		stats.Size += info.Size()
+		if stats.Oldest.IsZero() || info.ModTime().Before(stats.Oldest) {
+			stats.Oldest = info.ModTime()
+		}
+		if info.ModTime().After(stats.Newest) {
+			stats.Newest = info.ModTime()
+		}
+		if c.TTL > 0 && c.expiredFile(path) {
+			stats.Expired++
+		}
+	})
+	return stats, err
+}
+
+// Clear removes all cached entries
+func (c *Cache) Clear() error {
+	if err := os.RemoveAll(c.Dir); err != nil {
+		return fmt.Errorf("failed to clear cache: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+".json")
+}
+
+func (c *Cache) expired(created time.Time) bool {
+	return c.TTL > 0 && time.Since(created) > c.TTL
+}
+
+// expiredFile reads creation time of entry, access time kept in
+// modification time is not enough to tell the age
+func (c *Cache) expiredFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return true
+	}
+	return c.expired(entry.Created)
+}
+
+func (c *Cache) walk(fn func(path string, info fs.FileInfo)) error {
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".json") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		fn(path, info)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	return nil
+}
+
+// CachedClient wraps a client and serves identical requests from cache
+type CachedClient struct {
+	Client Client
+	Cache  *Cache
+	// Scope identifies provider, model and options, see CacheScope
+	Scope string
+}
+
+// NewCachedClient creates caching wrapper around client
+func NewCachedClient(client Client, cache *Cache, scope string) *CachedClient {
+	return &CachedClient{Client: client, Cache: cache, Scope: scope}
+}
+
+// CacheScope builds cache scope from provider name, its non secret
+// settings and generation options, so changing any of them misses cache
+func CacheScope(provider string, settings Settings, options Options) string {
+	public := Settings{}
+	p, _ := Lookup(provider)
+	for k, v := range settings {
+		if field, ok := p.Field(k); ok && field.Secret {
+			continue
+		}
+		public[k] = v
+	}
+
+	data, _ := json.Marshal(struct {
+		Provider string   `json:"provider"`
+		Settings Settings `json:"settings"`
+		Options  Options  `json:"options"`
+	}{provider, public, options})
+	return string(data)
+}
+
+// Complete implements Client interface, errors are never cached
+func (c *CachedClient) Complete(r Request) (*Response, error) {
+	key := c.key(r)
+	if response, ok := c.Cache.Get(key); ok {
+		response.Cached = true
+		return response, nil
+	}
+
+	response, err := c.Client.Complete(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Failing to cache must not fail the completion
+	c.Cache.Put(key, response)
+	return response, nil
+}
+
+func (c *CachedClient) key(r Request) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%s\x00", c.Scope, r.System, r.User)
+	for _, attachment := range r.Attachments {
+		fmt.Fprintf(hash, "%s\x00%s\x00%d\x00", attachment.Name, attachment.MIMEType, len(attachment.Data))
+		hash.Write(attachment.Data)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
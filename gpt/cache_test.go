@@ -0,0 +1,90 @@
+package gpt
+
+import (
+	"fmt"
+	"testing"
+)
+
+// countingClient answers with number of calls made so far
+type countingClient struct {
+	calls int
+}
+
+func (c *countingClient) Complete(r Request) (*Response, error) {
+	c.calls++
+	if r.User == "fail" {
+		return nil, fmt.Errorf("boom")
+	}
+	return &Response{Text: fmt.Sprintf("%s %d", r.User, c.calls), Usage: Usage{TotalTokens: 10}}, nil
+}
+
+func TestCachedClient(t *testing.T) {
+	inner := &countingClient{}
+	cache := NewCache(t.TempDir(), 0, 0)
+	client := NewCachedClient(inner, cache, CacheScope("openai", Settings{"key": "secret", "model": "gpt-4"}, Options{}))
+
+	first, err := client.Complete(Request{User: "task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := client.Complete(Request{User: "task"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 1 || second.Text != first.Text || !second.Cached || first.Cached {
+		t.Fatalf("expected second call served from cache, got %d calls, %+v", inner.calls, second)
+	}
+
+	if _, err := client.Complete(Request{User: "task", Attachments: []Attachment{{Name: "a.txt", Data: []byte("x")}}}); err != nil {
+		t.Fatal(err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected attachment to change cache key")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Complete(Request{User: "fail"}); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+	if inner.calls != 4 {
+		t.Fatalf("expected errors not to be cached, got %d calls", inner.calls)
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 2 {
+		t.Fatalf("expected 2 entries, got %d", stats.Entries)
+	}
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := cache.Stats(); stats.Entries != 0 {
+		t.Fatalf("expected empty cache after clear, got %d", stats.Entries)
+	}
+}
+
+func TestCacheScope(t *testing.T) {
+	base := CacheScope("openai", Settings{"key": "a", "model": "gpt-4"}, Options{})
+	if base != CacheScope("openai", Settings{"key": "b", "model": "gpt-4"}, Options{}) {
+		t.Error("expected secrets to be left out of scope")
+	}
+	if base == CacheScope("openai", Settings{"key": "a", "model": "gpt-4o"}, Options{}) {
+		t.Error("expected model to change scope")
+	}
+	if base == CacheScope("openai", Settings{"key": "a", "model": "gpt-4"}, Options{MaxTokens: 10}) {
+		t.Error("expected options to change scope")
+	}
+}
+
+func TestCacheSizeCap(t *testing.T) {
+	cache := NewCache(t.TempDir(), 0, 1)
+	if err := cache.Put("aa01", &Response{Text: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Get("aa01"); ok {
+		t.Fatal("expected entry over size cap to be evicted")
+	}
+}
@@ -0,0 +1,239 @@
+// Package cassette provides an http.RoundTripper that records provider
+// traffic to a file and replays it later, so gpt clients can be tested
+// without network access.
+//
+//	recorder, err := cassette.New("testdata/openai.json", cassette.Record)
+//	client := gpt.NewOpenAIClient(key, "gpt-4o")
+//	client.HTTPClient = recorder.Client()
+//	...
+//	err = recorder.Save()
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Mode selects whether transport talks to real endpoints or replays a file
+type Mode int
+
+const (
+	// Replay serves responses from the cassette file and never hits the network
+	Replay Mode = iota
+	// Record forwards requests to the real endpoint and remembers responses
+	Record
+)
+
+// secretParams are query parameters stripped from recorded URLs
+var secretParams = []string{"key", "api_key", "access_token"}
+
+// Interaction is a single request and response pair
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request, headers are never stored
+// since they carry credentials
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body"`
+}
+
+// Transport records or replays HTTP interactions
+type Transport struct {
+	Path string
+	Mode Mode
+	// Next performs real requests in Record mode, defaults to http.DefaultTransport
+	Next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New creates a transport backed by cassette file at path. In Replay mode
+// the file must exist, in Record mode it is overwritten by Save.
+func New(path string, mode Mode) (*Transport, error) {
+	t := &Transport{Path: path, Mode: mode}
+	if mode == Record {
+		return t, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	if err := json.Unmarshal(data, &t.interactions); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	t.used = make([]bool, len(t.interactions))
+	return t, nil
+}
+
+// Client returns http.Client using the transport
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Interactions returns copy of recorded or loaded interactions
+func (t *Transport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]Interaction(nil), t.interactions...)
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+	}
+
+	recorded := Request{
+		Method: req.Method,
+		URL:    scrubURL(req.URL),
+		Body:   string(body),
+	}
+
+	if t.Mode == Record {
+		return t.record(req, recorded, body)
+	}
+	return t.replay(req, recorded)
+}
+
+func (t *Transport) record(req *http.Request, recorded Request, body []byte) (*http.Response, error) {
+	next := t.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: recorded,
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: map[string]string{"Content-Type": resp.Header.Get("Content-Type")},
+			Body:    string(respBody),
+		},
+	}
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, interaction)
+	t.used = append(t.used, true)
+	t.mu.Unlock()
+
+	return interaction.Response.toHTTP(req), nil
+}
+
+func (t *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, interaction := range t.interactions {
+		if t.used[i] || !interaction.Request.matches(recorded) {
+			continue
+		}
+		t.used[i] = true
+		return interaction.Response.toHTTP(req), nil
+	}
+	return nil, fmt.Errorf("cassette %s has no unused interaction for %s %s", t.Path, recorded.Method, recorded.URL)
+}
+
+// Save writes recorded interactions to cassette file
+func (t *Transport) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	data, err := json.MarshalIndent(t.interactions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(t.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	if err := os.WriteFile(t.Path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// matches compares requests ignoring JSON formatting of bodies
+func (r Request) matches(other Request) bool {
+	if r.Method != other.Method || r.URL != other.URL {
+		return false
+	}
+	return normalizeJSON(r.Body) == normalizeJSON(other.Body)
+}
+
+func (r Response) toHTTP(req *http.Request) *http.Response {
+	header := http.Header{}
+	for k, v := range r.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
+
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	query := scrubbed.Query()
+	for _, param := range secretParams {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+	scrubbed.RawQuery = query.Encode()
+	return scrubbed.String()
+}
+
+func normalizeJSON(body string) string {
+	var value interface{}
+	if err := json.Unmarshal([]byte(body), &value); err != nil {
+		return body
+	}
+	normalized, _ := json.Marshal(value)
+	return string(normalized)
+}
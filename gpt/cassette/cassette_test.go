@@ -0,0 +1,95 @@
+package cassette_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/gpt/cassette"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"candidates":[{"content":{"parts":[{"text":"recorded"}]}}]}`))
+	}))
+
+	path := filepath.Join(t.TempDir(), "gemini.json")
+	recorder, err := cassette.New(path, cassette.Record)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := gpt.NewGeminiClient("secret-key", "gemini-pro")
+	client.BaseURL = server.URL
+	client.HTTPClient = recorder.Client()
+
+	request := gpt.Request{System: "s", User: "u"}
+	if _, err := client.Complete(request); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-key") {
+		t.Fatalf("cassette leaks api key:\n%s", data)
+	}
+
+	player, err := cassette.New(path, cassette.Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.HTTPClient = player.Client()
+
+	response, err := client.Complete(request)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if response.Text != "recorded" {
+		t.Fatalf("unexpected replayed text %q", response.Text)
+	}
+	if calls != 1 {
+		t.Fatalf("replay reached the server, calls = %d", calls)
+	}
+
+	// Every interaction is served once
+	if _, err := client.Complete(request); err == nil || !strings.Contains(err.Error(), "no unused interaction") {
+		t.Fatalf("expected exhausted cassette error, got %v", err)
+	}
+}
+
+func TestReplayMatchesRequestBody(t *testing.T) {
+	player, err := cassette.New(filepath.Join("testdata", "openai.json"), cassette.Replay)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := gpt.NewOpenAIClient("key", "gpt-4o")
+	client.HTTPClient = player.Client()
+
+	response, err := client.Complete(gpt.Request{System: "You run commands", User: "Task: list files"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Text != `{"thought":"List directory","command":"ls -la"}` || response.Usage.TotalTokens != 31 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+
+	if _, err := client.Complete(gpt.Request{System: "You run commands", User: "Task: something else"}); err == nil {
+		t.Fatalf("expected mismatch for different prompt")
+	}
+}
@@ -13,31 +13,88 @@ type ClaudeClient struct {
 	HTTPClient *http.Client
 	Model      string
 	BaseURL    string
+	Options    Options
 }
 
 type ClaudeRequest struct {
-	Model     string          `json:"model"`
-	MaxTokens int             `json:"max_tokens"`
-	Messages  []ClaudeMessage `json:"messages"`
-	System    string          `json:"system,omitempty"`
+	Model         string          `json:"model"`
+	MaxTokens     int             `json:"max_tokens"`
+	Messages      []ClaudeMessage `json:"messages"`
+	System        string          `json:"system,omitempty"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	TopP          *float64        `json:"top_p,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Thinking      *ClaudeThinking `json:"thinking,omitempty"`
+}
+
+type ClaudeThinking struct {
+	Type         string `json:"type"`
+	BudgetTokens int    `json:"budget_tokens"`
 }
 
 type ClaudeMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role string `json:"role"`
+	// Content is either a string or a list of ClaudeContentBlock
+	Content interface{} `json:"content"`
+}
+
+type ClaudeContentBlock struct {
+	Type   string             `json:"type"`
+	Text   string             `json:"text,omitempty"`
+	Source *ClaudeImageSource `json:"source,omitempty"`
+}
+
+type ClaudeImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
 }
 
 type ClaudeResponse struct {
 	Content []struct {
-		Text string `json:"text"`
-		Type string `json:"type"`
+		Text     string `json:"text"`
+		Thinking string `json:"thinking"`
+		Type     string `json:"type"`
 	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
 	Error *struct {
 		Type    string `json:"type"`
 		Message string `json:"message"`
 	} `json:"error,omitempty"`
 }
 
+func init() {
+	Register(Provider{
+		Name:        "claude",
+		Description: "Anthropic Claude Messages API",
+		Fields: []Field{
+			{Name: "key", LegacyKey: "claude_key", Prompt: "Claude API Key", Default: "your-claude-key", Placeholder: true, Required: true, Secret: true},
+			{Name: "model", LegacyKey: "claude_model", Prompt: "Claude Model", Default: "claude-3-sonnet-20240229", Required: true},
+		},
+		Images:         true,
+		MaxTemperature: 1,
+		Supports:       []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionStop, OptionThinkingBudget},
+		Ignores: func(_ Settings, o Options) []string {
+			if o.ThinkingBudget <= 0 {
+				return nil
+			}
+			ignored := []string{OptionTemperature, OptionTopP}
+			if o.MaxTokens > 0 && o.MaxTokens <= o.ThinkingBudget {
+				ignored = append(ignored, OptionMaxTokens)
+			}
+			return ignored
+		},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewClaudeClient(s["key"], s["model"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
+}
+
 // NewClaudeClient creates a new Claude client
 func NewClaudeClient(apiKey, model string) *ClaudeClient {
 	return &ClaudeClient{
@@ -45,28 +102,59 @@ func NewClaudeClient(apiKey, model string) *ClaudeClient {
 		HTTPClient: &http.Client{},
 		Model:      model,
 		BaseURL:    "https://api.anthropic.com/v1",
+		Options:    Options{MaxTokens: 4000},
 	}
 }
 
-// Complete implements GPTClient interface
-func (c *ClaudeClient) Complete(systemMessage, userMessage string) (string, error) {
+// Complete implements Client interface
+func (c *ClaudeClient) Complete(r Request) (*Response, error) {
 	request := ClaudeRequest{
 		Model:     c.Model,
-		MaxTokens: 4000,
-		System:    systemMessage,
+		MaxTokens: c.Options.MaxTokens,
+		System:    r.System,
 		Messages: []ClaudeMessage{
-			{Role: "user", Content: userMessage},
+			{Role: "user", Content: r.User},
 		},
+		Temperature:   c.Options.Temperature,
+		TopP:          c.Options.TopP,
+		StopSequences: c.Options.Stop,
+	}
+
+	if len(r.Attachments) > 0 {
+		var blocks []ClaudeContentBlock
+		for _, attachment := range r.Attachments {
+			if attachment.IsImage() {
+				blocks = append(blocks, ClaudeContentBlock{
+					Type:   "image",
+					Source: &ClaudeImageSource{Type: "base64", MediaType: attachment.MIMEType, Data: attachment.Base64()},
+				})
+			} else {
+				blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: attachment.Text()})
+			}
+		}
+		// Claude recommends placing images before the question
+		request.Messages[0].Content = append(blocks, ClaudeContentBlock{Type: "text", Text: r.User})
+	}
+
+	// Extended thinking is incompatible with temperature and top_p
+	// and the budget has to fit into max_tokens
+	if c.Options.ThinkingBudget > 0 {
+		request.Thinking = &ClaudeThinking{Type: "enabled", BudgetTokens: c.Options.ThinkingBudget}
+		request.Temperature = nil
+		request.TopP = nil
+		if request.MaxTokens <= c.Options.ThinkingBudget {
+			request.MaxTokens = c.Options.ThinkingBudget + 4000
+		}
 	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", c.BaseURL+"/messages", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -75,22 +163,38 @@ func (c *ClaudeClient) Complete(systemMessage, userMessage string) (string, erro
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var response ClaudeResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if response.Error != nil {
-		return "", fmt.Errorf("Claude API error: %s", response.Error.Message)
+		return nil, fmt.Errorf("Claude API error: %s", response.Error.Message)
+	}
+
+	result := &Response{
+		Usage: Usage{
+			InputTokens:  response.Usage.InputTokens,
+			OutputTokens: response.Usage.OutputTokens,
+			TotalTokens:  response.Usage.InputTokens + response.Usage.OutputTokens,
+		},
+	}
+	for _, content := range response.Content {
+		switch content.Type {
+		case "text":
+			result.Text += content.Text
+		case "thinking":
+			result.Reasoning += content.Thinking
+		}
 	}
 
-	if len(response.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
+	if result.Text == "" {
+		return nil, fmt.Errorf("no content in response")
 	}
 
-	return response.Content[0].Text, nil
+	return result, nil
 }
@@ -0,0 +1,121 @@
+package gpt
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClaudeClientComplete(t *testing.T) {
+	newClient := func(options Options) func(string, *http.Client) Client {
+		return func(baseURL string, httpClient *http.Client) Client {
+			client := NewClaudeClient("test-key", "claude-sonnet-4-0")
+			client.BaseURL = baseURL
+			client.HTTPClient = httpClient
+			client.Options = client.Options.Merge(options)
+			return client
+		}
+	}
+
+	runClientTests(t, newClient(Options{}), []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"content":[{"type":"text","text":"done"}],"usage":{"input_tokens":10,"output_tokens":3}}`,
+			want: &Response{
+				Text:  "done",
+				Usage: Usage{InputTokens: 10, OutputTokens: 3, TotalTokens: 13},
+			},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/messages" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if r.Header.Get("x-api-key") != "test-key" || r.Header.Get("anthropic-version") == "" {
+					t.Errorf("missing auth headers")
+				}
+				if field(body, "system") != "be helpful" {
+					t.Errorf("system prompt not sent: %v", body)
+				}
+			},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusBadRequest,
+			body:    `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}`,
+			wantErr: "Claude API error: max_tokens: field required",
+		},
+		{
+			name:    "empty content",
+			status:  http.StatusOK,
+			body:    `{"content":[]}`,
+			wantErr: "no content in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `not json`,
+			wantErr: "failed to decode response",
+		},
+		{
+			name:    "attachments",
+			request: Request{User: "what is wrong?", Attachments: []Attachment{testImage}},
+			status:  http.StatusOK,
+			body:    `{"content":[{"type":"text","text":"ok"}]}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "messages", 0, "content", 0, "source", "media_type") != "image/png" {
+					t.Errorf("image block not sent: %v", field(body, "messages", 0, "content"))
+				}
+				if field(body, "messages", 0, "content", 1, "text") != "what is wrong?" {
+					t.Errorf("question should follow image: %v", field(body, "messages", 0, "content"))
+				}
+			},
+		},
+	})
+
+	runClientTests(t, newClient(Options{ThinkingBudget: 8000, Temperature: Float(0.3)}), []clientTest{
+		{
+			name:   "extended thinking",
+			status: http.StatusOK,
+			body:   `{"content":[{"type":"thinking","thinking":"need ls","signature":"x"},{"type":"text","text":"answer"}]}`,
+			want:   &Response{Text: "answer", Reasoning: "need ls"},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "thinking", "budget_tokens") != 8000.0 {
+					t.Errorf("thinking not enabled: %v", body)
+				}
+				if field(body, "temperature") != nil {
+					t.Errorf("temperature must not be sent with thinking")
+				}
+				if field(body, "max_tokens").(float64) <= 8000 {
+					t.Errorf("max_tokens must exceed thinking budget")
+				}
+			},
+		},
+	})
+}
+
+func TestClaudeUnsupportedOptions(t *testing.T) {
+	provider, _ := Lookup("claude")
+	options := Options{Temperature: Float(0.2), MaxTokens: 1000, ThinkingBudget: 2000}
+
+	if got := provider.Unsupported(Settings{}, options); !reflect.DeepEqual(got, []string{OptionTemperature, OptionMaxTokens}) {
+		t.Errorf("expected temperature and max tokens to be reported with thinking budget, got %q", got)
+	}
+	options.ThinkingBudget = 0
+	if got := provider.Unsupported(Settings{}, options); len(got) != 0 {
+		t.Errorf("expected no options to be reported without thinking budget, got %q", got)
+	}
+}
+
+func TestClaudeTemperatureRange(t *testing.T) {
+	settings := Settings{"key": "test-key", "model": "claude-sonnet-4-0"}
+	if _, err := New("claude", settings, Options{Temperature: Float(1.5)}); err == nil || !strings.Contains(err.Error(), "between 0 and 1 for claude") {
+		t.Errorf("expected temperature above 1 to be refused, got %v", err)
+	}
+	if _, err := New("claude", settings, Options{Temperature: Float(0.9)}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := New("openai", Settings{"key": "test-key"}, Options{Temperature: Float(1.5)}); err != nil {
+		t.Errorf("expected openai to accept temperature 1.5, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package gpt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// CohereClient implements Client for Cohere Chat API v2
+type CohereClient struct {
+	APIKey     string
+	HTTPClient *http.Client
+	Model      string
+	BaseURL    string
+	Options    Options
+}
+
+type CohereRequest struct {
+	Model         string          `json:"model"`
+	Messages      []CohereMessage `json:"messages"`
+	MaxTokens     int             `json:"max_tokens,omitempty"`
+	Temperature   *float64        `json:"temperature,omitempty"`
+	P             *float64        `json:"p,omitempty"`
+	Seed          *int            `json:"seed,omitempty"`
+	StopSequences []string        `json:"stop_sequences,omitempty"`
+	Stream        bool            `json:"stream"`
+}
+
+type CohereMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type CohereResponse struct {
+	ID           string `json:"id"`
+	FinishReason string `json:"finish_reason"`
+	Message      struct {
+		Role    string `json:"role"`
+		Content []struct {
+			Type     string `json:"type"`
+			Text     string `json:"text"`
+			Thinking string `json:"thinking"`
+		} `json:"content"`
+	} `json:"message"`
+	Usage struct {
+		BilledUnits struct {
+			InputTokens  float64 `json:"input_tokens"`
+			OutputTokens float64 `json:"output_tokens"`
+		} `json:"billed_units"`
+		Tokens struct {
+			InputTokens  float64 `json:"input_tokens"`
+			OutputTokens float64 `json:"output_tokens"`
+		} `json:"tokens"`
+	} `json:"usage"`
+}
+
+type CohereError struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+}
+
+func init() {
+	Register(Provider{
+		Name:        "cohere",
+		Description: "Cohere Chat API",
+		Fields: []Field{
+			{Name: "key", LegacyKey: "cohere_key", Prompt: "Cohere API Key", Default: "your-cohere-key", Placeholder: true, Required: true, Secret: true},
+			{Name: "model", LegacyKey: "cohere_model", Prompt: "Cohere Model", Default: "command-r-plus", Required: true},
+		},
+		Supports: []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionSeed, OptionStop},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewCohereClient(s["key"], s["model"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
+}
+
+// NewCohereClient creates a new Cohere client
+func NewCohereClient(apiKey, model string) *CohereClient {
+	return &CohereClient{
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{},
+		Model:      model,
+		BaseURL:    "https://api.cohere.com/v2",
+		Options:    Options{Temperature: Float(0.7), MaxTokens: 4000},
+	}
+}
+
+// Complete implements Client interface
+func (c *CohereClient) Complete(r Request) (*Response, error) {
+	user, err := inlineAttachments("cohere", r)
+	if err != nil {
+		return nil, err
+	}
+
+	request := CohereRequest{
+		Model: c.Model,
+		Messages: []CohereMessage{
+			{Role: "system", Content: r.System},
+			{Role: "user", Content: user},
+		},
+		MaxTokens:     c.Options.MaxTokens,
+		Temperature:   c.Options.Temperature,
+		P:             c.Options.TopP,
+		Seed:          c.Options.Seed,
+		StopSequences: c.Options.Stop,
+		Stream:        false,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+"/chat", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var apiErr CohereError
+		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("Cohere API error: %s", apiErr.Message)
+		}
+		return nil, fmt.Errorf("Cohere API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response CohereResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Prefer actual token counts, billed units exclude system overhead
+	input, output := response.Usage.Tokens.InputTokens, response.Usage.Tokens.OutputTokens
+	if input == 0 && output == 0 {
+		input, output = response.Usage.BilledUnits.InputTokens, response.Usage.BilledUnits.OutputTokens
+	}
+	result := &Response{
+		Usage: Usage{
+			InputTokens:  int(input),
+			OutputTokens: int(output),
+			TotalTokens:  int(input + output),
+		},
+	}
+
+	var text, reasoning strings.Builder
+	for _, content := range response.Message.Content {
+		switch content.Type {
+		case "text":
+			text.WriteString(content.Text)
+		case "thinking":
+			reasoning.WriteString(content.Thinking)
+		}
+	}
+
+	if text.Len() == 0 {
+		return nil, fmt.Errorf("no content in response")
+	}
+
+	result.Text = text.String()
+	result.Reasoning = reasoning.String()
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package gpt
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCohereClientComplete(t *testing.T) {
+	newClient := func(baseURL string, httpClient *http.Client) Client {
+		client := NewCohereClient("test-key", "command-r-plus")
+		client.BaseURL = baseURL
+		client.HTTPClient = httpClient
+		return client
+	}
+
+	runClientTests(t, newClient, []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"id":"1","finish_reason":"COMPLETE","message":{"role":"assistant","content":[{"type":"text","text":"hi"}]},"usage":{"billed_units":{"input_tokens":3,"output_tokens":1},"tokens":{"input_tokens":70,"output_tokens":1}}}`,
+			want:   &Response{Text: "hi", Usage: Usage{InputTokens: 70, OutputTokens: 1, TotalTokens: 71}},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/chat" || r.Header.Get("Authorization") != "Bearer test-key" {
+					t.Errorf("unexpected request %s", r.URL.Path)
+				}
+				if field(body, "messages", 0, "role") != "system" {
+					t.Errorf("system message missing: %v", body)
+				}
+			},
+		},
+		{
+			name:   "billed units only",
+			status: http.StatusOK,
+			body:   `{"message":{"content":[{"type":"thinking","thinking":"hmm"},{"type":"text","text":"ok"}]},"usage":{"billed_units":{"input_tokens":3,"output_tokens":1}}}`,
+			want:   &Response{Text: "ok", Reasoning: "hmm", Usage: Usage{InputTokens: 3, OutputTokens: 1, TotalTokens: 4}},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusUnauthorized,
+			body:    `{"id":"x","message":"invalid api token"}`,
+			wantErr: "Cohere API error: invalid api token",
+		},
+		{
+			name:    "empty content",
+			status:  http.StatusOK,
+			body:    `{"message":{"content":[]}}`,
+			wantErr: "no content in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `<html>`,
+			wantErr: "failed to decode response",
+		},
+	})
+}
+
+func TestCohereListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/models" || r.URL.Query().Get("endpoint") != "chat" || r.Header.Get("Authorization") != "Bearer test-key" {
+			t.Errorf("unexpected request %s %v", r.URL, r.Header)
+		}
+		fmt.Fprint(w, `{"models":[{"name":"command-r-plus"},{"name":"command-a-03-2025"}]}`)
+	}))
+	defer server.Close()
+
+	client := NewCohereClient("test-key", "command-r-plus")
+	client.BaseURL = server.URL + "/v2"
+	models, err := client.ListModels()
+	if err != nil || fmt.Sprint(models) != "[command-a-03-2025 command-r-plus]" {
+		t.Fatalf("unexpected models %q, %v", models, err)
+	}
+}
@@ -13,13 +13,16 @@ type DeepSeekClient struct {
 	HTTPClient *http.Client
 	Model      string
 	BaseURL    string
+	Options    Options
 }
 
 type DeepSeekRequest struct {
 	Model       string            `json:"model"`
 	Messages    []DeepSeekMessage `json:"messages"`
 	MaxTokens   int               `json:"max_tokens,omitempty"`
-	Temperature float64           `json:"temperature,omitempty"`
+	Temperature *float64          `json:"temperature,omitempty"`
+	TopP        *float64          `json:"top_p,omitempty"`
+	Stop        []string          `json:"stop,omitempty"`
 	Stream      bool              `json:"stream"`
 }
 
@@ -31,10 +34,19 @@ type DeepSeekMessage struct {
 type DeepSeekResponse struct {
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens            int `json:"prompt_tokens"`
+		CompletionTokens        int `json:"completion_tokens"`
+		TotalTokens             int `json:"total_tokens"`
+		CompletionTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"completion_tokens_details"`
+	} `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
@@ -42,6 +54,29 @@ type DeepSeekResponse struct {
 	} `json:"error,omitempty"`
 }
 
+func init() {
+	Register(Provider{
+		Name:        "deepseek",
+		Description: "DeepSeek API",
+		Fields: []Field{
+			{Name: "key", LegacyKey: "deepseek_key", Prompt: "DeepSeek API Key", Default: "your-deepseek-key", Placeholder: true, Required: true, Secret: true},
+			{Name: "model", LegacyKey: "deepseek_model", Prompt: "DeepSeek Model", Default: "deepseek-chat", Required: true},
+		},
+		Supports: []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionStop},
+		Ignores: func(s Settings, _ Options) []string {
+			if s["model"] == "deepseek-reasoner" {
+				return []string{OptionTemperature, OptionTopP}
+			}
+			return nil
+		},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewDeepSeekClient(s["key"], s["model"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
+}
+
 // NewDeepSeekClient creates a new DeepSeek client
 func NewDeepSeekClient(apiKey, model string) *DeepSeekClient {
 	return &DeepSeekClient{
@@ -49,30 +84,42 @@ func NewDeepSeekClient(apiKey, model string) *DeepSeekClient {
 		HTTPClient: &http.Client{},
 		Model:      model,
 		BaseURL:    "https://api.deepseek.com/v1",
+		Options:    Options{Temperature: Float(0.7), MaxTokens: 4000},
 	}
 }
 
-// Complete implements GPTClient interface
-func (c *DeepSeekClient) Complete(systemMessage, userMessage string) (string, error) {
+// Complete implements Client interface
+func (c *DeepSeekClient) Complete(r Request) (*Response, error) {
+	user, err := inlineAttachments("deepseek", r)
+	if err != nil {
+		return nil, err
+	}
+
 	request := DeepSeekRequest{
 		Model: c.Model,
 		Messages: []DeepSeekMessage{
-			{Role: "system", Content: systemMessage},
-			{Role: "user", Content: userMessage},
+			{Role: "system", Content: r.System},
+			{Role: "user", Content: user},
 		},
-		MaxTokens:   4000,
-		Temperature: 0.7,
-		Stream:      false,
+		MaxTokens: c.Options.MaxTokens,
+		Stop:      c.Options.Stop,
+		Stream:    false,
+	}
+
+	// Reasoner silently ignores sampling parameters, don't pretend they apply
+	if c.Model != "deepseek-reasoner" {
+		request.Temperature = c.Options.Temperature
+		request.TopP = c.Options.TopP
 	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -80,22 +127,32 @@ func (c *DeepSeekClient) Complete(systemMessage, userMessage string) (string, er
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var response DeepSeekResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if response.Error != nil {
-		return "", fmt.Errorf("DeepSeek API error: %s", response.Error.Message)
+		return nil, fmt.Errorf("DeepSeek API error: %s", response.Error.Message)
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return nil, fmt.Errorf("no choices in response")
 	}
 
-	return response.Choices[0].Message.Content, nil
+	message := response.Choices[0].Message
+	return &Response{
+		Text:      message.Content,
+		Reasoning: message.ReasoningContent,
+		Usage: Usage{
+			InputTokens:     response.Usage.PromptTokens,
+			OutputTokens:    response.Usage.CompletionTokens,
+			ReasoningTokens: response.Usage.CompletionTokensDetails.ReasoningTokens,
+			TotalTokens:     response.Usage.TotalTokens,
+		},
+	}, nil
 }
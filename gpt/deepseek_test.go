@@ -0,0 +1,95 @@
+package gpt
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestDeepSeekClientComplete(t *testing.T) {
+	newClient := func(model string) func(string, *http.Client) Client {
+		return func(baseURL string, httpClient *http.Client) Client {
+			client := NewDeepSeekClient("test-key", model)
+			client.BaseURL = baseURL
+			client.HTTPClient = httpClient
+			return client
+		}
+	}
+
+	runClientTests(t, newClient("deepseek-chat"), []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"choices":[{"message":{"content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":4,"completion_tokens":1,"total_tokens":5}}`,
+			want:   &Response{Text: "hi", Usage: Usage{InputTokens: 4, OutputTokens: 1, TotalTokens: 5}},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/chat/completions" || field(body, "stream") != false {
+					t.Errorf("unexpected request %s %v", r.URL.Path, body)
+				}
+			},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusPaymentRequired,
+			body:    `{"error":{"message":"Insufficient Balance","type":"unknown_error","code":"invalid_request_error"}}`,
+			wantErr: "DeepSeek API error: Insufficient Balance",
+		},
+		{
+			name:    "no choices",
+			status:  http.StatusOK,
+			body:    `{"choices":[]}`,
+			wantErr: "no choices in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `[`,
+			wantErr: "failed to decode response",
+		},
+		{
+			name:    "image rejected",
+			request: Request{User: "look", Attachments: []Attachment{testImage}},
+			status:  http.StatusOK,
+			body:    `{}`,
+			wantErr: "does not support image attachments",
+		},
+		{
+			name:    "text inlined",
+			request: Request{User: "fix it", Attachments: []Attachment{testText}},
+			status:  http.StatusOK,
+			body:    `{"choices":[{"message":{"content":"ok"}}]}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				content, _ := field(body, "messages", 1, "content").(string)
+				if content != "fix it\n\n"+testText.Text() {
+					t.Errorf("text attachment not inlined: %q", content)
+				}
+			},
+		},
+	})
+
+	runClientTests(t, newClient("deepseek-reasoner"), []clientTest{
+		{
+			name:   "reasoner",
+			status: http.StatusOK,
+			body:   `{"choices":[{"message":{"content":"answer","reasoning_content":"thinking"}}]}`,
+			want:   &Response{Text: "answer", Reasoning: "thinking"},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "temperature") != nil {
+					t.Errorf("temperature sent to reasoner")
+				}
+			},
+		},
+	})
+}
+
+func TestDeepSeekUnsupportedOptions(t *testing.T) {
+	provider, _ := Lookup("deepseek")
+	options := Options{Temperature: Float(0.2), TopP: Float(0.9), MaxTokens: 100}
+
+	if got := provider.Unsupported(Settings{"model": "deepseek-reasoner"}, options); !reflect.DeepEqual(got, []string{OptionTemperature, OptionTopP}) {
+		t.Errorf("expected sampling options to be reported for reasoner, got %q", got)
+	}
+	if got := provider.Unsupported(Settings{"model": "deepseek-chat"}, options); len(got) != 0 {
+		t.Errorf("expected no options to be reported for chat model, got %q", got)
+	}
+}
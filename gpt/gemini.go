@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // GeminiClient implements GPTClient for Google Gemini API
@@ -13,6 +14,7 @@ type GeminiClient struct {
 	HTTPClient *http.Client
 	Model      string
 	BaseURL    string
+	Options    Options
 }
 
 type GeminiRequest struct {
@@ -27,7 +29,18 @@ type GeminiContent struct {
 }
 
 type GeminiPart struct {
-	Text string `json:"text"`
+	Text       string            `json:"text,omitempty"`
+	InlineData *GeminiInlineData `json:"inlineData,omitempty"`
+}
+
+type GeminiInlineData struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+type GeminiThinkingConfig struct {
+	ThinkingBudget  int  `json:"thinkingBudget,omitempty"`
+	IncludeThoughts bool `json:"includeThoughts,omitempty"`
 }
 
 type GeminiSystemInstruction struct {
@@ -35,19 +48,30 @@ type GeminiSystemInstruction struct {
 }
 
 type GeminiGenerationConfig struct {
-	Temperature     float64 `json:"temperature,omitempty"`
-	MaxOutputTokens int     `json:"maxOutputTokens,omitempty"`
+	Temperature     *float64              `json:"temperature,omitempty"`
+	TopP            *float64              `json:"topP,omitempty"`
+	MaxOutputTokens int                   `json:"maxOutputTokens,omitempty"`
+	Seed            *int                  `json:"seed,omitempty"`
+	StopSequences   []string              `json:"stopSequences,omitempty"`
+	ThinkingConfig  *GeminiThinkingConfig `json:"thinkingConfig,omitempty"`
 }
 
 type GeminiResponse struct {
 	Candidates []struct {
 		Content struct {
 			Parts []struct {
-				Text string `json:"text"`
+				Text    string `json:"text"`
+				Thought bool   `json:"thought"`
 			} `json:"parts"`
 		} `json:"content"`
 		FinishReason string `json:"finishReason"`
 	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int `json:"promptTokenCount"`
+		CandidatesTokenCount int `json:"candidatesTokenCount"`
+		ThoughtsTokenCount   int `json:"thoughtsTokenCount"`
+		TotalTokenCount      int `json:"totalTokenCount"`
+	} `json:"usageMetadata"`
 	Error *struct {
 		Code    int    `json:"code"`
 		Message string `json:"message"`
@@ -55,6 +79,24 @@ type GeminiResponse struct {
 	} `json:"error,omitempty"`
 }
 
+func init() {
+	Register(Provider{
+		Name:        "gemini",
+		Description: "Google Gemini API",
+		Fields: []Field{
+			{Name: "key", LegacyKey: "gemini_key", Prompt: "Gemini API Key", Default: "your-gemini-key", Placeholder: true, Required: true, Secret: true},
+			{Name: "model", LegacyKey: "gemini_model", Prompt: "Gemini Model", Default: "gemini-pro", Required: true},
+		},
+		Images:   true,
+		Supports: []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionSeed, OptionStop, OptionThinkingBudget},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewGeminiClient(s["key"], s["model"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
+}
+
 // NewGeminiClient creates a new Gemini client
 func NewGeminiClient(apiKey, model string) *GeminiClient {
 	return &GeminiClient{
@@ -62,61 +104,102 @@ func NewGeminiClient(apiKey, model string) *GeminiClient {
 		HTTPClient: &http.Client{},
 		Model:      model,
 		BaseURL:    "https://generativelanguage.googleapis.com/v1beta",
+		Options:    Options{Temperature: Float(0.7), MaxTokens: 4000},
 	}
 }
 
-// Complete implements GPTClient interface
-func (c *GeminiClient) Complete(systemMessage, userMessage string) (string, error) {
+// Complete implements Client interface
+func (c *GeminiClient) Complete(r Request) (*Response, error) {
 	request := GeminiRequest{
 		Contents: []GeminiContent{
 			{
-				Parts: []GeminiPart{{Text: userMessage}},
+				Parts: []GeminiPart{{Text: r.User}},
 				Role:  "user",
 			},
 		},
 		GenerationConfig: GeminiGenerationConfig{
-			Temperature:     0.7,
-			MaxOutputTokens: 4000,
+			Temperature:     c.Options.Temperature,
+			TopP:            c.Options.TopP,
+			MaxOutputTokens: c.Options.MaxTokens,
+			Seed:            c.Options.Seed,
+			StopSequences:   c.Options.Stop,
 		},
 	}
 
-	if systemMessage != "" {
+	for _, attachment := range r.Attachments {
+		part := GeminiPart{Text: attachment.Text()}
+		if attachment.IsImage() {
+			part = GeminiPart{InlineData: &GeminiInlineData{MimeType: attachment.MIMEType, Data: attachment.Base64()}}
+		}
+		request.Contents[0].Parts = append(request.Contents[0].Parts, part)
+	}
+
+	// Thinking models only return thought summaries when asked to
+	if c.Options.ThinkingBudget > 0 || isGeminiThinkingModel(c.Model) {
+		request.GenerationConfig.ThinkingConfig = &GeminiThinkingConfig{
+			ThinkingBudget:  c.Options.ThinkingBudget,
+			IncludeThoughts: true,
+		}
+	}
+
+	if r.System != "" {
 		request.SystemInstruction = &GeminiSystemInstruction{
-			Parts: []GeminiPart{{Text: systemMessage}},
+			Parts: []GeminiPart{{Text: r.System}},
 		}
 	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", c.BaseURL, c.Model, c.APIKey)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var response GeminiResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if response.Error != nil {
-		return "", fmt.Errorf("Gemini API error: %s", response.Error.Message)
+		return nil, fmt.Errorf("Gemini API error: %s", response.Error.Message)
 	}
 
 	if len(response.Candidates) == 0 || len(response.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no content in response")
+		return nil, fmt.Errorf("no content in response")
+	}
+
+	result := &Response{
+		Usage: Usage{
+			InputTokens:     response.UsageMetadata.PromptTokenCount,
+			OutputTokens:    response.UsageMetadata.CandidatesTokenCount + response.UsageMetadata.ThoughtsTokenCount,
+			ReasoningTokens: response.UsageMetadata.ThoughtsTokenCount,
+			TotalTokens:     response.UsageMetadata.TotalTokenCount,
+		},
+	}
+	for _, part := range response.Candidates[0].Content.Parts {
+		if part.Thought {
+			result.Reasoning += part.Text
+		} else {
+			result.Text += part.Text
+		}
 	}
 
-	return response.Candidates[0].Content.Parts[0].Text, nil
+	return result, nil
+}
+
+// isGeminiThinkingModel reports whether model thinks by default
+func isGeminiThinkingModel(model string) bool {
+	return strings.Contains(model, "thinking") || strings.HasPrefix(model, "gemini-2.5") || strings.HasPrefix(model, "gemini-3")
 }
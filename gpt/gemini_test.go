@@ -0,0 +1,90 @@
+package gpt
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGeminiClientComplete(t *testing.T) {
+	newClient := func(model string) func(string, *http.Client) Client {
+		return func(baseURL string, httpClient *http.Client) Client {
+			client := NewGeminiClient("test-key", model)
+			client.BaseURL = baseURL
+			client.HTTPClient = httpClient
+			return client
+		}
+	}
+
+	runClientTests(t, newClient("gemini-pro"), []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"candidates":[{"content":{"parts":[{"text":"hel"},{"text":"lo"}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"totalTokenCount":5}}`,
+			want:   &Response{Text: "hello", Usage: Usage{InputTokens: 3, OutputTokens: 2, TotalTokens: 5}},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/models/gemini-pro:generateContent" || r.URL.Query().Get("key") != "test-key" {
+					t.Errorf("unexpected url %s", r.URL)
+				}
+				if field(body, "systemInstruction", "parts", 0, "text") != "be helpful" {
+					t.Errorf("system instruction not sent: %v", body)
+				}
+				if field(body, "generationConfig", "thinkingConfig") != nil {
+					t.Errorf("thinking config sent to non thinking model")
+				}
+			},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusBadRequest,
+			body:    `{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}`,
+			wantErr: "Gemini API error: API key not valid",
+		},
+		{
+			name:    "no candidates",
+			status:  http.StatusOK,
+			body:    `{"candidates":[]}`,
+			wantErr: "no content in response",
+		},
+		{
+			name:    "candidate without parts",
+			status:  http.StatusOK,
+			body:    `{"candidates":[{"content":{},"finishReason":"SAFETY"}]}`,
+			wantErr: "no content in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `{"candidates":[{]}`,
+			wantErr: "failed to decode response",
+		},
+		{
+			name:    "attachments",
+			request: Request{User: "see", Attachments: []Attachment{testImage}},
+			status:  http.StatusOK,
+			body:    `{"candidates":[{"content":{"parts":[{"text":"ok"}]}}]}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "contents", 0, "parts", 1, "inlineData", "mimeType") != "image/png" {
+					t.Errorf("inline image not sent: %v", field(body, "contents"))
+				}
+			},
+		},
+	})
+
+	runClientTests(t, newClient("gemini-2.5-flash"), []clientTest{
+		{
+			name:   "thinking model",
+			status: http.StatusOK,
+			body:   `{"candidates":[{"content":{"parts":[{"text":"plan","thought":true},{"text":"answer"}]}}],"usageMetadata":{"promptTokenCount":3,"candidatesTokenCount":2,"thoughtsTokenCount":7,"totalTokenCount":12}}`,
+			want: &Response{
+				Text:      "answer",
+				Reasoning: "plan",
+				Usage:     Usage{InputTokens: 3, OutputTokens: 9, ReasoningTokens: 7, TotalTokens: 12},
+			},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "generationConfig", "thinkingConfig", "includeThoughts") != true {
+					t.Errorf("thought summaries not requested: %v", body)
+				}
+			},
+		},
+	})
+}
@@ -2,5 +2,31 @@ package gpt
 
 // Client interface for all GPT providers
 type Client interface {
-	Complete(systemMessage, userMessage string) (string, error)
+	Complete(request Request) (*Response, error)
+}
+
+// Request represents a single completion request
+type Request struct {
+	System      string
+	User        string
+	Attachments []Attachment
+}
+
+// Response represents a completion returned by a provider
+type Response struct {
+	// Text is the answer of the model
+	Text string
+	// Reasoning holds thinking output of reasoning models, if exposed
+	Reasoning string
+	Usage     Usage
+	// Cached is set when response was served from cache
+	Cached bool `json:"-"`
+}
+
+// Usage represents token usage reported by a provider for a single completion
+type Usage struct {
+	InputTokens     int
+	OutputTokens    int
+	ReasoningTokens int
+	TotalTokens     int
 }
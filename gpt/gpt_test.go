@@ -0,0 +1,114 @@
+package gpt
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// clientTest describes a single exchange between a client and a fake provider
+type clientTest struct {
+	name    string
+	request Request
+	status  int
+	body    string
+	want    *Response
+	wantErr string
+	// check inspects request that reached the server
+	check func(t *testing.T, r *http.Request, body map[string]interface{})
+}
+
+// runClientTests serves each test body from httptest server and compares
+// client results, newClient must point the client to given base URL
+func runClientTests(t *testing.T, newClient func(baseURL string, httpClient *http.Client) Client, tests []clientTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				gotRequest *http.Request
+				gotBody    map[string]interface{}
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				gotRequest = r
+				gotBody = map[string]interface{}{}
+				if err := json.Unmarshal(data, &gotBody); err != nil {
+					t.Errorf("client sent invalid JSON: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			request := tt.request
+			if request.User == "" {
+				request = Request{System: "be helpful", User: "list files"}
+			}
+
+			client := newClient(server.URL, server.Client())
+			got, err := client.Complete(request)
+
+			if tt.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error containing %q, got response %+v", tt.wantErr, got)
+				}
+				if !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %q", tt.wantErr, err.Error())
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tt.want != nil && !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("unexpected response:\n got: %+v\nwant: %+v", got, tt.want)
+				}
+			}
+
+			if tt.check != nil && gotRequest != nil {
+				tt.check(t, gotRequest, gotBody)
+			}
+		})
+	}
+}
+
+// field walks decoded JSON by keys and slice indexes
+func field(body interface{}, path ...interface{}) interface{} {
+	for _, p := range path {
+		switch key := p.(type) {
+		case string:
+			m, ok := body.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			body = m[key]
+		case int:
+			s, ok := body.([]interface{})
+			if !ok || key >= len(s) {
+				return nil
+			}
+			body = s[key]
+		}
+	}
+	return body
+}
+
+var testImage = Attachment{Name: "error.png", MIMEType: "image/png", Data: []byte("png")}
+
+var testText = Attachment{Name: "build.log", MIMEType: "text/plain", Data: []byte("undefined: foo")}
+
+func TestLookupPrice(t *testing.T) {
+	for model, want := range map[string]float64{"o1-2024-12-17": 15, "o1-mini-2024-09-12": 1.1, "gpt-4o-mini": 0.15} {
+		if price, ok := LookupPrice(model); !ok || price.Input != want {
+			t.Errorf("%s: input price %v, want %v", model, price.Input, want)
+		}
+	}
+	if _, ok := LookupPrice("llama3"); ok {
+		t.Error("local model has a price")
+	}
+}
@@ -0,0 +1,188 @@
+package gpt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	MistralBaseURL   = "https://api.mistral.ai/v1"
+	CodestralBaseURL = "https://codestral.mistral.ai/v1"
+)
+
+// MistralClient implements Client for Mistral La Plateforme and Codestral APIs
+type MistralClient struct {
+	APIKey     string
+	HTTPClient *http.Client
+	Model      string
+	BaseURL    string
+	Options    Options
+}
+
+type MistralRequest struct {
+	Model       string           `json:"model"`
+	Messages    []MistralMessage `json:"messages"`
+	MaxTokens   int              `json:"max_tokens,omitempty"`
+	Temperature *float64         `json:"temperature,omitempty"`
+	TopP        *float64         `json:"top_p,omitempty"`
+	RandomSeed  *int             `json:"random_seed,omitempty"`
+	Stop        []string         `json:"stop,omitempty"`
+	Stream      bool             `json:"stream"`
+}
+
+type MistralMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type MistralResponse struct {
+	Choices []struct {
+		Message struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
+
+// MistralChunk is a content chunk returned by Magistral reasoning models
+type MistralChunk struct {
+	Type     string         `json:"type"`
+	Text     string         `json:"text"`
+	Thinking []MistralChunk `json:"thinking"`
+}
+
+type MistralError struct {
+	Object  string `json:"object"`
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+func init() {
+	Register(Provider{
+		Name:        "mistral",
+		Description: "Mistral La Plateforme and Codestral",
+		Fields: []Field{
+			{Name: "key", LegacyKey: "mistral_key", Prompt: "Mistral API Key", Default: "your-mistral-key", Placeholder: true, Required: true, Secret: true},
+			{Name: "model", LegacyKey: "mistral_model", Prompt: "Mistral Model (use codestral-latest for Codestral)", Default: "mistral-large-latest", Required: true},
+			{Name: "url", LegacyKey: "mistral_url", Prompt: "Mistral API URL (use " + CodestralBaseURL + " for Codestral)", Default: MistralBaseURL, Required: true},
+		},
+		Supports: []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionSeed, OptionStop},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewMistralClient(s["key"], s["model"], s["url"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
+}
+
+// NewMistralClient creates a new Mistral client, an empty baseURL
+// selects La Plateforme
+func NewMistralClient(apiKey, model, baseURL string) *MistralClient {
+	if baseURL == "" {
+		baseURL = MistralBaseURL
+	}
+	return &MistralClient{
+		APIKey:     apiKey,
+		HTTPClient: &http.Client{},
+		Model:      model,
+		BaseURL:    baseURL,
+		Options:    Options{Temperature: Float(0.7), MaxTokens: 4000},
+	}
+}
+
+// Complete implements Client interface
+func (c *MistralClient) Complete(r Request) (*Response, error) {
+	user, err := inlineAttachments("mistral", r)
+	if err != nil {
+		return nil, err
+	}
+
+	request := MistralRequest{
+		Model: c.Model,
+		Messages: []MistralMessage{
+			{Role: "system", Content: r.System},
+			{Role: "user", Content: user},
+		},
+		MaxTokens:   c.Options.MaxTokens,
+		Temperature: c.Options.Temperature,
+		TopP:        c.Options.TopP,
+		RandomSeed:  c.Options.Seed,
+		Stop:        c.Options.Stop,
+		Stream:      false,
+	}
+
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var apiErr MistralError
+		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Message != "" {
+			return nil, fmt.Errorf("Mistral API error: %s", apiErr.Message)
+		}
+		return nil, fmt.Errorf("Mistral API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response MistralResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	result := &Response{
+		Usage: Usage{
+			InputTokens:  response.Usage.PromptTokens,
+			OutputTokens: response.Usage.CompletionTokens,
+			TotalTokens:  response.Usage.TotalTokens,
+		},
+	}
+
+	// Content is a plain string unless the model returns thinking chunks
+	content := response.Choices[0].Message.Content
+	if err := json.Unmarshal(content, &result.Text); err != nil {
+		var chunks []MistralChunk
+		if err := json.Unmarshal(content, &chunks); err != nil {
+			return nil, fmt.Errorf("failed to decode message content: %w", err)
+		}
+		for _, chunk := range chunks {
+			switch chunk.Type {
+			case "text":
+				result.Text += chunk.Text
+			case "thinking":
+				for _, thought := range chunk.Thinking {
+					result.Reasoning += thought.Text
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
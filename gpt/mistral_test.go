@@ -0,0 +1,58 @@
+package gpt
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestMistralClientComplete(t *testing.T) {
+	newClient := func(baseURL string, httpClient *http.Client) Client {
+		client := NewMistralClient("test-key", "mistral-large-latest", baseURL)
+		client.HTTPClient = httpClient
+		return client
+	}
+
+	runClientTests(t, newClient, []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"choices":[{"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`,
+			want:   &Response{Text: "hi", Usage: Usage{InputTokens: 5, OutputTokens: 1, TotalTokens: 6}},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/chat/completions" || r.Header.Get("Authorization") != "Bearer test-key" {
+					t.Errorf("unexpected request %s", r.URL.Path)
+				}
+			},
+		},
+		{
+			name:   "thinking chunks",
+			status: http.StatusOK,
+			body:   `{"choices":[{"message":{"content":[{"type":"thinking","thinking":[{"type":"text","text":"hmm"}]},{"type":"text","text":"answer"}]}}]}`,
+			want:   &Response{Text: "answer", Reasoning: "hmm"},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusBadRequest,
+			body:    `{"object":"error","message":"Invalid model: foo","type":"invalid_model","param":null,"code":"1500"}`,
+			wantErr: "Mistral API error: Invalid model: foo",
+		},
+		{
+			name:    "validation error",
+			status:  http.StatusUnprocessableEntity,
+			body:    `{"detail":[{"loc":["body","messages"],"msg":"field required"}]}`,
+			wantErr: "status 422",
+		},
+		{
+			name:    "no choices",
+			status:  http.StatusOK,
+			body:    `{"choices":[]}`,
+			wantErr: "no choices in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `{"choices"`,
+			wantErr: "failed to decode response",
+		},
+	})
+}
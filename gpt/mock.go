@@ -0,0 +1,135 @@
+package gpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MockResponse is a single scripted model reply. Either Text is returned
+// verbatim, or Thought, Command, Answer and Result are rendered into agent
+// JSON protocol, a non-empty Error makes the call fail instead.
+type MockResponse struct {
+	Text         string `yaml:"text,omitempty" json:"text,omitempty"`
+	Thought      string `yaml:"thought,omitempty" json:"thought,omitempty"`
+	Command      string `yaml:"command,omitempty" json:"command,omitempty"`
+	Answer       string `yaml:"answer,omitempty" json:"answer,omitempty"`
+	Reasoning    string `yaml:"reasoning,omitempty" json:"reasoning,omitempty"`
+	Error        string `yaml:"error,omitempty" json:"error,omitempty"`
+	InputTokens  int    `yaml:"input_tokens,omitempty" json:"input_tokens,omitempty"`
+	OutputTokens int    `yaml:"output_tokens,omitempty" json:"output_tokens,omitempty"`
+	// Result is the structured result sent with TASK_COMPLETE
+	Result map[string]interface{} `yaml:"result,omitempty" json:"result,omitempty"`
+}
+
+// MockScript is the content of a mock script file
+type MockScript struct {
+	Responses []MockResponse `yaml:"responses" json:"responses"`
+}
+
+// MockClient implements Client by replaying scripted responses in order,
+// it never touches the network
+type MockClient struct {
+	Responses []MockResponse
+
+	mu       sync.Mutex
+	requests []Request
+}
+
+func init() {
+	Register(Provider{
+		Name:        "mock",
+		Description: "Scripted responses for tests, no network access",
+		Hidden:      true,
+		Fields: []Field{
+			{Name: "script", Prompt: "Mock script file (YAML or JSON)", Required: true},
+		},
+		New: func(s Settings, o Options) (Client, error) {
+			responses, err := LoadMockScript(s["script"])
+			if err != nil {
+				return nil, err
+			}
+			return NewMockClient(responses...), nil
+		},
+	})
+}
+
+// NewMockClient creates a client replaying given responses
+func NewMockClient(responses ...MockResponse) *MockClient {
+	return &MockClient{Responses: responses}
+}
+
+// LoadMockScript reads scripted responses from YAML or JSON file
+func LoadMockScript(path string) ([]MockResponse, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mock script: %w", err)
+	}
+
+	// YAML is a superset of JSON, one decoder handles both
+	var script MockScript
+	if err := yaml.Unmarshal(data, &script); err != nil {
+		return nil, fmt.Errorf("failed to parse mock script %s: %w", path, err)
+	}
+	if len(script.Responses) == 0 {
+		return nil, fmt.Errorf("mock script %s has no responses", path)
+	}
+	return script.Responses, nil
+}
+
+// Complete implements Client interface
+func (c *MockClient) Complete(r Request) (*Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.requests = append(c.requests, r)
+	call := len(c.requests)
+	if call > len(c.Responses) {
+		return nil, fmt.Errorf("mock script exhausted after %d responses", len(c.Responses))
+	}
+
+	scripted := c.Responses[call-1]
+	if scripted.Error != "" {
+		return nil, fmt.Errorf("%s", scripted.Error)
+	}
+
+	text := scripted.Text
+	if text == "" {
+		fields := map[string]interface{}{
+			"thought": scripted.Thought,
+			"command": scripted.Command,
+		}
+		if scripted.Answer != "" {
+			fields["answer"] = scripted.Answer
+		}
+		if scripted.Result != nil {
+			fields["result"] = scripted.Result
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render mock response: %w", err)
+		}
+		text = string(data)
+	}
+
+	return &Response{
+		Text:      text,
+		Reasoning: scripted.Reasoning,
+		Usage: Usage{
+			InputTokens:  scripted.InputTokens,
+			OutputTokens: scripted.OutputTokens,
+			TotalTokens:  scripted.InputTokens + scripted.OutputTokens,
+		},
+	}, nil
+}
+
+// Requests returns requests received so far
+func (c *MockClient) Requests() []Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Request(nil), c.requests...)
+}
@@ -0,0 +1,60 @@
+package gpt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMockScript(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"script.yml":  "responses:\n  - thought: t\n    command: ls\n  - error: boom\n",
+		"script.json": `{"responses": [{"thought": "t", "command": "ls"}, {"error": "boom"}]}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			client, err := New("mock", Settings{"script": path}, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := client.Complete(Request{User: "task"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.Text != `{"command":"ls","thought":"t"}` {
+				t.Fatalf("unexpected text %q", response.Text)
+			}
+			if _, err := client.Complete(Request{}); err == nil || err.Error() != "boom" {
+				t.Fatalf("expected scripted error, got %v", err)
+			}
+			if _, err := client.Complete(Request{}); err == nil {
+				t.Fatalf("expected exhausted script error")
+			}
+		})
+	}
+}
+
+func TestMockProviderRequiresScript(t *testing.T) {
+	if _, err := New("mock", Settings{}, Options{}); err == nil {
+		t.Fatalf("expected error without script")
+	}
+}
+
+func TestMockProviderIsHidden(t *testing.T) {
+	if _, ok := Lookup("mock"); !ok {
+		t.Fatal("mock provider not registered")
+	}
+	for _, name := range Names() {
+		if name == "mock" {
+			t.Fatalf("hidden mock provider listed in %q", Names())
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package gpt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ModelLister is implemented by clients able to list models available
+// to the configured account
+type ModelLister interface {
+	ListModels() ([]string, error)
+}
+
+// modelList covers list responses of OpenAI compatible APIs as well
+// as Ollama, Gemini and Cohere ones
+type modelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+}
+
+func fetchModels(httpClient *http.Client, url string, headers map[string]string) ([]string, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+	}
+
+	var list modelList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	var models []string
+	for _, model := range list.Data {
+		models = append(models, model.ID)
+	}
+	for _, model := range list.Models {
+		models = append(models, strings.TrimPrefix(model.Name, "models/"))
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// ListModels implements ModelLister interface
+func (c *OpenAIClient) ListModels() ([]string, error) {
+	return fetchModels(c.HTTPClient, c.BaseURL+"/models", map[string]string{"Authorization": "Bearer " + c.APIKey})
+}
+
+// ListModels implements ModelLister interface
+func (c *DeepSeekClient) ListModels() ([]string, error) {
+	return fetchModels(c.HTTPClient, c.BaseURL+"/models", map[string]string{"Authorization": "Bearer " + c.APIKey})
+}
+
+// ListModels implements ModelLister interface
+func (c *MistralClient) ListModels() ([]string, error) {
+	return fetchModels(c.HTTPClient, c.BaseURL+"/models", map[string]string{"Authorization": "Bearer " + c.APIKey})
+}
+
+// ListModels implements ModelLister interface
+func (c *ClaudeClient) ListModels() ([]string, error) {
+	return fetchModels(c.HTTPClient, c.BaseURL+"/models", map[string]string{
+		"x-api-key":         c.APIKey,
+		"anthropic-version": "2023-06-01",
+	})
+}
+
+// ListModels implements ModelLister interface
+func (c *GeminiClient) ListModels() ([]string, error) {
+	return fetchModels(c.HTTPClient, fmt.Sprintf("%s/models?key=%s", c.BaseURL, c.APIKey), nil)
+}
+
+// ListModels implements ModelLister interface, models are listed by
+// the v1 API only and filtered to chat ones
+func (c *CohereClient) ListModels() ([]string, error) {
+	base := strings.TrimSuffix(c.BaseURL, "/v2")
+	return fetchModels(c.HTTPClient, base+"/v1/models?endpoint=chat", map[string]string{"Authorization": "Bearer " + c.APIKey})
+}
+
+// ListModels implements ModelLister interface
+func (c *OllamaClient) ListModels() ([]string, error) {
+	return fetchModels(c.HTTPClient, c.BaseURL+"/api/tags", nil)
+}
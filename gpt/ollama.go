@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 )
 
@@ -12,18 +13,51 @@ type OllamaClient struct {
 	BaseURL    string
 	HTTPClient *http.Client
 	Model      string
+	Options    Options
 }
 
 type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model   string         `json:"model"`
+	Prompt  string         `json:"prompt"`
+	Stream  bool           `json:"stream"`
+	Think   *bool          `json:"think,omitempty"`
+	Images  []string       `json:"images,omitempty"`
+	Options *OllamaOptions `json:"options,omitempty"`
+}
+
+type OllamaOptions struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	NumPredict  int      `json:"num_predict,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
 }
 
 type OllamaResponse struct {
-	Response string `json:"response"`
-	Done     bool   `json:"done"`
-	Error    string `json:"error,omitempty"`
+	Response        string `json:"response"`
+	Thinking        string `json:"thinking"`
+	Done            bool   `json:"done"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+	Error           string `json:"error,omitempty"`
+}
+
+func init() {
+	Register(Provider{
+		Name:        "ollama",
+		Description: "Local Ollama server",
+		Fields: []Field{
+			{Name: "url", LegacyKey: "ollama_url", Prompt: "Ollama API URL", Default: "http://localhost:11434", Required: true},
+			{Name: "model", LegacyKey: "ollama_model", Prompt: "Ollama Model", Default: "llama2", Required: true},
+		},
+		Images:   true,
+		Supports: []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionSeed, OptionStop, OptionReasoningEffort},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewOllamaClient(s["url"], s["model"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
 }
 
 // NewOllamaClient creates a new Ollama client
@@ -36,46 +70,95 @@ func NewOllamaClient(baseURL, model string) *OllamaClient {
 }
 
 // Complete implements Client interface
-func (c *OllamaClient) Complete(systemMessage, userMessage string) (string, error) {
+func (c *OllamaClient) Complete(r Request) (*Response, error) {
+	// Combine system and user messages for Ollama
+	// Text files go into the prompt, images are passed to vision models
+	user := r.User
+	var images []string
+	for _, attachment := range r.Attachments {
+		if attachment.IsImage() {
+			images = append(images, attachment.Base64())
+		} else {
+			user += "\n\n" + attachment.Text()
+		}
+	}
+
 	// Combine system and user messages for Ollama
-	prompt := fmt.Sprintf("System: %s\n\nUser: %s\n\nAssistant:", systemMessage, userMessage)
+	prompt := fmt.Sprintf("System: %s\n\nUser: %s\n\nAssistant:", r.System, user)
 
 	request := OllamaRequest{
 		Model:  c.Model,
 		Prompt: prompt,
 		Stream: false,
+		Images: images,
+	}
+
+	// Ollama only has an on/off switch for thinking models
+	if c.Options.ReasoningEffort != "" {
+		think := true
+		request.Think = &think
+	}
+
+	// Leave model defaults from Modelfile untouched unless configured
+	if len(c.Options.Names()) > 0 {
+		request.Options = &OllamaOptions{
+			Temperature: c.Options.Temperature,
+			TopP:        c.Options.TopP,
+			NumPredict:  c.Options.MaxTokens,
+			Seed:        c.Options.Seed,
+			Stop:        c.Options.Stop,
+		}
 	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", c.BaseURL+"/api/generate", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API request failed with status %d", resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		var apiErr OllamaResponse
+		if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error != "" {
+			return nil, fmt.Errorf("API error: %s", apiErr.Error)
+		}
+		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
 	}
 
 	var response OllamaResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if response.Error != "" {
-		return "", fmt.Errorf("API error: %s", response.Error)
+		return nil, fmt.Errorf("API error: %s", response.Error)
+	}
+
+	// Models without native thinking support emit reasoning inline
+	text, reasoning := splitThinkTags(response.Response)
+	if response.Thinking != "" {
+		reasoning = response.Thinking
 	}
 
-	return response.Response, nil
+	return &Response{
+		Text:      text,
+		Reasoning: reasoning,
+		Usage: Usage{
+			InputTokens:  response.PromptEvalCount,
+			OutputTokens: response.EvalCount,
+			TotalTokens:  response.PromptEvalCount + response.EvalCount,
+		},
+	}, nil
 }
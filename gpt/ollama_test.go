@@ -0,0 +1,75 @@
+package gpt
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOllamaClientComplete(t *testing.T) {
+	newClient := func(baseURL string, httpClient *http.Client) Client {
+		client := NewOllamaClient(baseURL, "llava")
+		client.HTTPClient = httpClient
+		return client
+	}
+
+	runClientTests(t, newClient, []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"response":"hello","done":true,"prompt_eval_count":8,"eval_count":2}`,
+			want:   &Response{Text: "hello", Usage: Usage{InputTokens: 8, OutputTokens: 2, TotalTokens: 10}},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/api/generate" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				prompt, _ := field(body, "prompt").(string)
+				if !strings.Contains(prompt, "System: be helpful") || !strings.Contains(prompt, "User: list files") {
+					t.Errorf("unexpected prompt %q", prompt)
+				}
+				if field(body, "options") != nil {
+					t.Errorf("options must be omitted by default")
+				}
+			},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusNotFound,
+			body:    `{"error":"model \"llava\" not found, try pulling it first"}`,
+			wantErr: `API error: model "llava" not found`,
+		},
+		{
+			name:    "error in body",
+			status:  http.StatusOK,
+			body:    `{"error":"out of memory"}`,
+			wantErr: "API error: out of memory",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `{"response":`,
+			wantErr: "failed to decode response",
+		},
+		{
+			name:   "inline think tags",
+			status: http.StatusOK,
+			body:   `{"response":"<think>check files</think>\n{\"thought\":\"t\"}","done":true}`,
+			want:   &Response{Text: `{"thought":"t"}`, Reasoning: "check files"},
+		},
+		{
+			name:    "attachments",
+			request: Request{User: "see", Attachments: []Attachment{testImage, testText}},
+			status:  http.StatusOK,
+			body:    `{"response":"ok","done":true}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "images", 0) != "cG5n" {
+					t.Errorf("image not sent: %v", body)
+				}
+				prompt, _ := field(body, "prompt").(string)
+				if !strings.Contains(prompt, "undefined: foo") {
+					t.Errorf("text attachment not inlined: %q", prompt)
+				}
+			},
+		},
+	})
+}
@@ -13,18 +13,35 @@ type OpenAIClient struct {
 	HTTPClient *http.Client
 	Model      string
 	BaseURL    string
+	Options    Options
 }
 
 type OpenAIRequest struct {
-	Model       string          `json:"model"`
-	Messages    []OpenAIMessage `json:"messages"`
-	MaxTokens   int             `json:"max_tokens,omitempty"`
-	Temperature float64         `json:"temperature,omitempty"`
+	Model               string          `json:"model"`
+	Messages            []OpenAIMessage `json:"messages"`
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	Seed                *int            `json:"seed,omitempty"`
+	Stop                []string        `json:"stop,omitempty"`
+	ReasoningEffort     string          `json:"reasoning_effort,omitempty"`
 }
 
 type OpenAIMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role string `json:"role"`
+	// Content is either a string or a list of OpenAIContentPart
+	Content interface{} `json:"content"`
+}
+
+type OpenAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *OpenAIImageURL `json:"image_url,omitempty"`
+}
+
+type OpenAIImageURL struct {
+	URL string `json:"url"`
 }
 
 type OpenAIResponse struct {
@@ -33,12 +50,44 @@ type OpenAIResponse struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens            int `json:"prompt_tokens"`
+		CompletionTokens        int `json:"completion_tokens"`
+		TotalTokens             int `json:"total_tokens"`
+		CompletionTokensDetails struct {
+			ReasoningTokens int `json:"reasoning_tokens"`
+		} `json:"completion_tokens_details"`
+	} `json:"usage"`
 	Error *struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 	} `json:"error,omitempty"`
 }
 
+func init() {
+	Register(Provider{
+		Name:        "openai",
+		Description: "OpenAI Chat Completions API",
+		Fields: []Field{
+			{Name: "key", LegacyKey: "openai_key", Prompt: "OpenAI API Key", Default: "your-openai-key", Placeholder: true, Required: true, Secret: true},
+			{Name: "model", LegacyKey: "openai_model", Prompt: "OpenAI Model", Default: "gpt-3.5-turbo", Required: true},
+		},
+		Images:   true,
+		Supports: []string{OptionTemperature, OptionTopP, OptionMaxTokens, OptionSeed, OptionStop, OptionReasoningEffort},
+		Ignores: func(s Settings, _ Options) []string {
+			if isOpenAIReasoningModel(s["model"]) {
+				return []string{OptionTemperature, OptionTopP, OptionStop}
+			}
+			return []string{OptionReasoningEffort}
+		},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewOpenAIClient(s["key"], s["model"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
+}
+
 // NewOpenAIClient creates a new OpenAI client
 func NewOpenAIClient(apiKey, model string) *OpenAIClient {
 	return &OpenAIClient{
@@ -46,29 +95,64 @@ func NewOpenAIClient(apiKey, model string) *OpenAIClient {
 		HTTPClient: &http.Client{},
 		Model:      model,
 		BaseURL:    "https://api.openai.com/v1",
+		Options:    Options{Temperature: Float(0.7), MaxTokens: 4000},
 	}
 }
 
-// Complete implements GPTClient interface
-func (c *OpenAIClient) Complete(systemMessage, userMessage string) (string, error) {
+// Complete implements Client interface
+func (c *OpenAIClient) Complete(r Request) (*Response, error) {
 	request := OpenAIRequest{
 		Model: c.Model,
 		Messages: []OpenAIMessage{
-			{Role: "system", Content: systemMessage},
-			{Role: "user", Content: userMessage},
+			{Role: "system", Content: r.System},
+			{Role: "user", Content: r.User},
 		},
-		MaxTokens:   4000,
-		Temperature: 0.7,
+		Seed: c.Options.Seed,
+	}
+
+	// Early reasoning models accept neither system nor developer
+	// messages, instructions go first in the user message
+	user := r.User
+	if !openAIInstructions(c.Model) {
+		user = r.System + "\n\n" + r.User
+		request.Messages = []OpenAIMessage{{Role: "user", Content: user}}
+	}
+
+	if len(r.Attachments) > 0 {
+		parts := []OpenAIContentPart{{Type: "text", Text: user}}
+		for _, attachment := range r.Attachments {
+			if attachment.IsImage() {
+				parts = append(parts, OpenAIContentPart{Type: "image_url", ImageURL: &OpenAIImageURL{URL: attachment.DataURL()}})
+			} else {
+				parts = append(parts, OpenAIContentPart{Type: "text", Text: attachment.Text()})
+			}
+		}
+		request.Messages[len(request.Messages)-1].Content = parts
+	}
+
+	// Reasoning models reject sampling parameters and count hidden
+	// reasoning against max_completion_tokens instead of max_tokens
+	if isOpenAIReasoningModel(c.Model) {
+		if request.Messages[0].Role == "system" {
+			request.Messages[0].Role = "developer"
+		}
+		request.MaxCompletionTokens = c.Options.MaxTokens
+		request.ReasoningEffort = c.Options.ReasoningEffort
+	} else {
+		request.MaxTokens = c.Options.MaxTokens
+		request.Temperature = c.Options.Temperature
+		request.TopP = c.Options.TopP
+		request.Stop = c.Options.Stop
 	}
 
 	jsonData, err := json.Marshal(request)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -76,22 +160,30 @@ func (c *OpenAIClient) Complete(systemMessage, userMessage string) (string, erro
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var response OpenAIResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	if response.Error != nil {
-		return "", fmt.Errorf("OpenAI API error: %s", response.Error.Message)
+		return nil, fmt.Errorf("OpenAI API error: %s", response.Error.Message)
 	}
 
 	if len(response.Choices) == 0 {
-		return "", fmt.Errorf("no choices in response")
+		return nil, fmt.Errorf("no choices in response")
 	}
 
-	return response.Choices[0].Message.Content, nil
+	return &Response{
+		Text: response.Choices[0].Message.Content,
+		Usage: Usage{
+			InputTokens:     response.Usage.PromptTokens,
+			OutputTokens:    response.Usage.CompletionTokens,
+			ReasoningTokens: response.Usage.CompletionTokensDetails.ReasoningTokens,
+			TotalTokens:     response.Usage.TotalTokens,
+		},
+	}, nil
 }
@@ -0,0 +1,125 @@
+package gpt
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestOpenAIClientComplete(t *testing.T) {
+	newClient := func(model string) func(string, *http.Client) Client {
+		return func(baseURL string, httpClient *http.Client) Client {
+			client := NewOpenAIClient("test-key", model)
+			client.BaseURL = baseURL
+			client.HTTPClient = httpClient
+			return client
+		}
+	}
+
+	runClientTests(t, newClient("gpt-4o"), []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"choices":[{"message":{"content":"{\"thought\":\"t\",\"command\":\"ls\"}"}}],"usage":{"prompt_tokens":12,"completion_tokens":5,"total_tokens":17}}`,
+			want: &Response{
+				Text:  `{"thought":"t","command":"ls"}`,
+				Usage: Usage{InputTokens: 12, OutputTokens: 5, TotalTokens: 17},
+			},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.URL.Path != "/chat/completions" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer test-key" {
+					t.Errorf("missing bearer token")
+				}
+				if field(body, "temperature") != 0.7 || field(body, "max_tokens") != 4000.0 {
+					t.Errorf("default options not sent: %v", body)
+				}
+				if field(body, "messages", 0, "role") != "system" {
+					t.Errorf("expected system message first")
+				}
+			},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"message":"Incorrect API key provided","type":"invalid_request_error"}}`,
+			wantErr: "OpenAI API error: Incorrect API key provided",
+		},
+		{
+			name:    "no choices",
+			status:  http.StatusOK,
+			body:    `{"choices":[]}`,
+			wantErr: "no choices in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `{"choices":[{"message":`,
+			wantErr: "failed to decode response",
+		},
+		{
+			name:    "attachments",
+			request: Request{System: "s", User: "why does it fail?", Attachments: []Attachment{testImage, testText}},
+			status:  http.StatusOK,
+			body:    `{"choices":[{"message":{"content":"ok"}}]}`,
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "messages", 1, "content", 1, "image_url", "url") != "data:image/png;base64,cG5n" {
+					t.Errorf("image part not sent: %v", field(body, "messages", 1, "content"))
+				}
+				if field(body, "messages", 1, "content", 2, "type") != "text" {
+					t.Errorf("text part not sent: %v", field(body, "messages", 1, "content"))
+				}
+			},
+		},
+	})
+
+	runClientTests(t, newClient("o3-mini"), []clientTest{
+		{
+			name:   "reasoning model",
+			status: http.StatusOK,
+			body:   `{"choices":[{"message":{"content":"ok"}}],"usage":{"prompt_tokens":1,"completion_tokens":30,"total_tokens":31,"completion_tokens_details":{"reasoning_tokens":25}}}`,
+			want: &Response{
+				Text:  "ok",
+				Usage: Usage{InputTokens: 1, OutputTokens: 30, ReasoningTokens: 25, TotalTokens: 31},
+			},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "temperature") != nil || field(body, "max_tokens") != nil {
+					t.Errorf("reasoning model got sampling parameters: %v", body)
+				}
+				if field(body, "max_completion_tokens") != 4000.0 {
+					t.Errorf("expected max_completion_tokens, got %v", body)
+				}
+				if field(body, "messages", 0, "role") != "developer" {
+					t.Errorf("expected developer message for reasoning model")
+				}
+			},
+		},
+	})
+
+	runClientTests(t, newClient("o1-mini"), []clientTest{
+		{
+			name:    "model without instructions",
+			request: Request{System: "rules", User: "task"},
+			status:  http.StatusOK,
+			body:    `{"choices":[{"message":{"content":"ok"}}]}`,
+			want:    &Response{Text: "ok"},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if field(body, "messages", 0, "role") != "user" || field(body, "messages", 0, "content") != "rules\n\ntask" {
+					t.Errorf("expected instructions in the user message, got %v", field(body, "messages"))
+				}
+			},
+		},
+	})
+}
+
+func TestOpenAIUnsupportedOptions(t *testing.T) {
+	provider, _ := Lookup("openai")
+	options := Options{Temperature: Float(0.2), MaxTokens: 100, ReasoningEffort: "low"}
+
+	if got := provider.Unsupported(Settings{"model": "o3-mini"}, options); len(got) != 1 || got[0] != OptionTemperature {
+		t.Errorf("expected temperature to be reported for reasoning model, got %q", got)
+	}
+	if got := provider.Unsupported(Settings{"model": "gpt-4o"}, options); len(got) != 1 || got[0] != OptionReasoningEffort {
+		t.Errorf("expected reasoning effort to be reported for gpt-4o, got %q", got)
+	}
+}
@@ -0,0 +1,111 @@
+package gpt
+
+import "fmt"
+
+// Generation option names as used in config files and warnings
+const (
+	OptionTemperature     = "temperature"
+	OptionTopP            = "top_p"
+	OptionMaxTokens       = "max_tokens"
+	OptionSeed            = "seed"
+	OptionStop            = "stop"
+	OptionReasoningEffort = "reasoning_effort"
+	OptionThinkingBudget  = "thinking_budget"
+)
+
+// Options controls generation of completions, unset values
+// leave client defaults in place
+type Options struct {
+	Temperature     *float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	TopP            *float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
+	MaxTokens       int      `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+	Seed            *int     `yaml:"seed,omitempty" json:"seed,omitempty"`
+	Stop            []string `yaml:"stop,omitempty" json:"stop,omitempty"`
+	ReasoningEffort string   `yaml:"reasoning_effort,omitempty" json:"reasoning_effort,omitempty"`
+	ThinkingBudget  int      `yaml:"thinking_budget,omitempty" json:"thinking_budget,omitempty"`
+}
+
+// Float returns pointer to v, handy for filling Options
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Int returns pointer to v, handy for filling Options
+func Int(v int) *int {
+	return &v
+}
+
+// Names returns names of options that are set
+func (o Options) Names() []string {
+	var names []string
+	if o.Temperature != nil {
+		names = append(names, OptionTemperature)
+	}
+	if o.TopP != nil {
+		names = append(names, OptionTopP)
+	}
+	if o.MaxTokens != 0 {
+		names = append(names, OptionMaxTokens)
+	}
+	if o.Seed != nil {
+		names = append(names, OptionSeed)
+	}
+	if len(o.Stop) > 0 {
+		names = append(names, OptionStop)
+	}
+	if o.ReasoningEffort != "" {
+		names = append(names, OptionReasoningEffort)
+	}
+	if o.ThinkingBudget != 0 {
+		names = append(names, OptionThinkingBudget)
+	}
+	return names
+}
+
+// Merge returns options with values set in override replacing own ones
+func (o Options) Merge(override Options) Options {
+	if override.Temperature != nil {
+		o.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		o.TopP = override.TopP
+	}
+	if override.MaxTokens != 0 {
+		o.MaxTokens = override.MaxTokens
+	}
+	if override.Seed != nil {
+		o.Seed = override.Seed
+	}
+	if len(override.Stop) > 0 {
+		o.Stop = override.Stop
+	}
+	if override.ReasoningEffort != "" {
+		o.ReasoningEffort = override.ReasoningEffort
+	}
+	if override.ThinkingBudget != 0 {
+		o.ThinkingBudget = override.ThinkingBudget
+	}
+	return o
+}
+
+// Validate checks that option values are within accepted ranges
+func (o Options) Validate() error {
+	if o.Temperature != nil && (*o.Temperature < 0 || *o.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if o.TopP != nil && (*o.TopP < 0 || *o.TopP > 1) {
+		return fmt.Errorf("top_p must be between 0 and 1")
+	}
+	if o.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must not be negative")
+	}
+	if o.ThinkingBudget < 0 {
+		return fmt.Errorf("thinking_budget must not be negative")
+	}
+	switch o.ReasoningEffort {
+	case "", "minimal", "low", "medium", "high":
+	default:
+		return fmt.Errorf("reasoning_effort must be one of minimal, low, medium, high")
+	}
+	return nil
+}
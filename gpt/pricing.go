@@ -0,0 +1,59 @@
+package gpt
+
+import "strings"
+
+// Price is cost of a model in US dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// prices lists published prices of common models, entries match model
+// names by prefix and the longest prefix wins
+var prices = map[string]Price{
+	"gpt-4o":            {Input: 2.5, Output: 10},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.6},
+	"gpt-4.1":           {Input: 2, Output: 8},
+	"gpt-4.1-mini":      {Input: 0.4, Output: 1.6},
+	"gpt-3.5-turbo":     {Input: 0.5, Output: 1.5},
+	"o1":                {Input: 15, Output: 60},
+	"o1-mini":           {Input: 1.1, Output: 4.4},
+	"o3-mini":           {Input: 1.1, Output: 4.4},
+	"o4-mini":           {Input: 1.1, Output: 4.4},
+	"claude-3-opus":     {Input: 15, Output: 75},
+	"claude-3-sonnet":   {Input: 3, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"deepseek-chat":     {Input: 0.27, Output: 1.1},
+	"deepseek-reasoner": {Input: 0.55, Output: 2.19},
+	"gemini-1.5-pro":    {Input: 1.25, Output: 5},
+	"gemini-1.5-flash":  {Input: 0.075, Output: 0.3},
+	"gemini-2.0-flash":  {Input: 0.1, Output: 0.4},
+	"mistral-large":     {Input: 2, Output: 6},
+	"mistral-small":     {Input: 0.2, Output: 0.6},
+	"codestral":         {Input: 0.3, Output: 0.9},
+}
+
+// LookupPrice returns price of model, local and unknown models have none
+func LookupPrice(model string) (Price, bool) {
+	var best string
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	if best == "" {
+		return Price{}, false
+	}
+	return prices[best], true
+}
+
+// Cost estimates cost of usage. It is approximate, providers differ in
+// whether output tokens include reasoning ones
+func (p Price) Cost(u Usage) float64 {
+	return (float64(u.InputTokens)*p.Input + float64(u.OutputTokens)*p.Output) / 1e6
+}
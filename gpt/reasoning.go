@@ -0,0 +1,47 @@
+package gpt
+
+import "strings"
+
+// isOpenAIReasoningModel reports whether OpenAI model belongs to reasoning
+// families that reject temperature and expect max_completion_tokens
+func isOpenAIReasoningModel(model string) bool {
+	for _, prefix := range []string{"o1", "o3", "o4", "gpt-5"} {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// openAIInstructions reports whether OpenAI model accepts system or
+// developer messages, o1-mini and o1-preview reject both
+func openAIInstructions(model string) bool {
+	return !strings.HasPrefix(model, "o1-mini") && !strings.HasPrefix(model, "o1-preview")
+}
+
+// splitThinkTags separates <think>...</think> blocks that open source
+// reasoning models emit inline from the actual answer
+func splitThinkTags(text string) (answer, reasoning string) {
+	if !strings.Contains(text, "<think>") {
+		return text, ""
+	}
+
+	var thoughts []string
+	for {
+		start := strings.Index(text, "<think>")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(text[start:], "</think>")
+		if end == -1 {
+			// Unterminated block, everything after the tag is reasoning
+			thoughts = append(thoughts, strings.TrimSpace(text[start+len("<think>"):]))
+			text = text[:start]
+			break
+		}
+		end += start
+		thoughts = append(thoughts, strings.TrimSpace(text[start+len("<think>"):end]))
+		text = text[:start] + text[end+len("</think>"):]
+	}
+	return strings.TrimSpace(text), strings.Join(thoughts, "\n")
+}
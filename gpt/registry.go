@@ -0,0 +1,224 @@
+package gpt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Settings holds provider specific configuration values keyed by field name
+type Settings map[string]string
+
+// Field describes a single configuration value of a provider
+type Field struct {
+	// Name is the key of the value inside provider settings
+	Name string
+	// LegacyKey is the flat top-level key used by older config files
+	LegacyKey string
+	// Prompt is shown by the setup wizard
+	Prompt string
+	// Default is used when the value is not configured
+	Default string
+	// Placeholder marks Default as a dummy value written by older
+	// versions, it is never used and config migration removes it
+	Placeholder bool
+	// Required fields must have a non-empty value
+	Required bool
+	// Secret fields hold credentials
+	Secret bool
+}
+
+// Provider describes a GPT provider: its configuration schema and
+// how to construct a client from it
+type Provider struct {
+	Name        string
+	Description string
+	Fields      []Field
+	// Supports lists generation options understood by the provider
+	Supports []string
+	// Ignores returns supported options the provider drops for given
+	// settings and options, like sampling options of some models,
+	// optional
+	Ignores func(Settings, Options) []string
+	// MaxTemperature is the highest temperature the provider accepts,
+	// 2 when zero
+	MaxTemperature float64
+	// Images tells whether provider accepts image attachments
+	Images bool
+	// Hidden providers work when named but are left out of provider
+	// lists, defaults and the schema
+	Hidden bool
+	// Validate performs provider specific checks, optional
+	Validate func(Settings) error
+	// New creates a client from validated settings, options
+	// override client defaults
+	New func(Settings, Options) (Client, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Provider{}
+)
+
+// Register makes a provider available by name. It is meant to be called
+// from init functions, both by built-in providers and by programs embedding
+// g8t as a library. Register panics if the name is already taken.
+func Register(p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if p.Name == "" || p.New == nil {
+		panic("gpt: Register provider without name or constructor")
+	}
+	if _, exists := registry[p.Name]; exists {
+		panic("gpt: Register called twice for provider " + p.Name)
+	}
+	registry[p.Name] = p
+}
+
+// Lookup returns registered provider by name
+func Lookup(name string) (Provider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	p, ok := registry[name]
+	return p, ok
+}
+
+// Providers returns all registered providers sorted by name
+func Providers() []Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	providers := make([]Provider, 0, len(registry))
+	for _, p := range registry {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool {
+		return providers[i].Name < providers[j].Name
+	})
+	return providers
+}
+
+// Names returns names of registered providers that are not hidden
+// sorted alphabetically
+func Names() []string {
+	var names []string
+	for _, p := range Providers() {
+		if !p.Hidden {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// New creates a client for named provider, settings and options
+// are validated first
+func New(name string, settings Settings, options Options) (Client, error) {
+	p, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+	if err := p.Check(settings); err != nil {
+		return nil, err
+	}
+	if err := p.CheckOptions(options); err != nil {
+		return nil, fmt.Errorf("invalid generation options: %w", err)
+	}
+	return p.New(p.WithDefaults(settings), options)
+}
+
+// Field returns provider field by name
+func (p Provider) Field(name string) (Field, bool) {
+	for _, f := range p.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Defaults returns settings filled with default values of all fields
+// that have a real default
+func (p Provider) Defaults() Settings {
+	settings := Settings{}
+	for _, f := range p.Fields {
+		if !f.Placeholder {
+			settings[f.Name] = f.Default
+		}
+	}
+	return settings
+}
+
+// WithDefaults returns a copy of settings with missing values
+// taken from field defaults
+func (p Provider) WithDefaults(settings Settings) Settings {
+	result := p.Defaults()
+	for k, v := range settings {
+		if v != "" {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+// Check validates settings against provider schema
+func (p Provider) Check(settings Settings) error {
+	settings = p.WithDefaults(settings)
+
+	var invalid []string
+	for _, f := range p.Fields {
+		value := settings[f.Name]
+		if f.Required && value == "" {
+			invalid = append(invalid, f.Name)
+		}
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%s provider requires valid %s", p.Name, strings.Join(invalid, " and "))
+	}
+
+	if p.Validate != nil {
+		return p.Validate(settings)
+	}
+	return nil
+}
+
+// CheckOptions validates options against ranges accepted by provider
+func (p Provider) CheckOptions(options Options) error {
+	if err := options.Validate(); err != nil {
+		return err
+	}
+	if p.MaxTemperature > 0 && options.Temperature != nil && *options.Temperature > p.MaxTemperature {
+		return fmt.Errorf("temperature must be between 0 and %g for %s provider", p.MaxTemperature, p.Name)
+	}
+	return nil
+}
+
+// Unsupported returns names of set options the provider ignores with
+// given settings
+func (p Provider) Unsupported(settings Settings, options Options) []string {
+	var ignored []string
+	if p.Ignores != nil {
+		ignored = p.Ignores(settings, options)
+	}
+	var unsupported []string
+	for _, name := range options.Names() {
+		supported := false
+		for _, s := range p.Supports {
+			if s == name {
+				supported = true
+				break
+			}
+		}
+		for _, s := range ignored {
+			if s == name {
+				supported = false
+			}
+		}
+		if !supported {
+			unsupported = append(unsupported, name)
+		}
+	}
+	return unsupported
+}
@@ -6,41 +6,48 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 )
 
 const (
 	YandexGPTEndpoint = "https://llm.api.cloud.yandex.net/foundationModels/v1/completion"
 )
 
-// Message represents a message in the conversation
-type Message struct {
+// YandexMessage represents a message in the conversation
+type YandexMessage struct {
 	Role string `json:"role"`
 	Text string `json:"text"`
 }
 
-// CompletionOptions represents the options for the completion
-type CompletionOptions struct {
-	MaxTokens   int     `json:"maxTokens"`
-	Temperature float64 `json:"temperature"`
+// YandexCompletionOptions represents the options for the completion
+type YandexCompletionOptions struct {
+	MaxTokens        int                     `json:"maxTokens,omitempty"`
+	Temperature      *float64                `json:"temperature,omitempty"`
+	ReasoningOptions *YandexReasoningOptions `json:"reasoningOptions,omitempty"`
 }
 
-// Request represents the request to the Yandex GPT API
-type Request struct {
-	ModelURI          string            `json:"modelUri"`
-	CompletionOptions CompletionOptions `json:"completionOptions"`
-	Messages          []Message         `json:"messages"`
+// YandexReasoningOptions controls reasoning of models that support it
+type YandexReasoningOptions struct {
+	Mode string `json:"mode"`
 }
 
-// Alternative represents an alternative response
-type Alternative struct {
-	Message Message `json:"message"`
-	Status  string  `json:"status"`
+// YandexRequest represents the request to the Yandex GPT API
+type YandexRequest struct {
+	ModelURI          string                  `json:"modelUri"`
+	CompletionOptions YandexCompletionOptions `json:"completionOptions"`
+	Messages          []YandexMessage         `json:"messages"`
 }
 
-// Response represents the response from the Yandex GPT API
-type Response struct {
+// YandexAlternative represents an alternative response
+type YandexAlternative struct {
+	Message YandexMessage `json:"message"`
+	Status  string        `json:"status"`
+}
+
+// YandexResponse represents the response from the Yandex GPT API
+type YandexResponse struct {
 	Result struct {
-		Alternatives []Alternative `json:"alternatives"`
+		Alternatives []YandexAlternative `json:"alternatives"`
 		Usage        struct {
 			InputTextTokens         string `json:"inputTextTokens"`
 			CompletionTokens        string `json:"completionTokens"`
@@ -59,6 +66,26 @@ type YandexClient struct {
 	IAMToken   string
 	HTTPClient *http.Client
 	ModelURI   string
+	Endpoint   string
+	Options    Options
+}
+
+func init() {
+	Register(Provider{
+		Name:        "yandex",
+		Description: "Yandex Cloud Foundation Models",
+		Fields: []Field{
+			{Name: "folder_id", LegacyKey: "folder_id", Prompt: "Yandex Cloud Folder ID", Default: "your-folder-id", Placeholder: true, Required: true},
+			{Name: "iam_token", LegacyKey: "iam_token", Prompt: "Yandex Cloud IAM Token", Default: "your-iam-token", Placeholder: true, Required: true, Secret: true},
+		},
+		MaxTemperature: 1,
+		Supports:       []string{OptionTemperature, OptionMaxTokens, OptionReasoningEffort},
+		New: func(s Settings, o Options) (Client, error) {
+			client := NewYandexClient(s["folder_id"], s["iam_token"])
+			client.Options = client.Options.Merge(o)
+			return client, nil
+		},
+	})
 }
 
 // NewYandexClient creates a new Yandex GPT client
@@ -68,37 +95,49 @@ func NewYandexClient(folderID, iamToken string) *YandexClient {
 		IAMToken:   iamToken,
 		HTTPClient: &http.Client{},
 		ModelURI:   "gpt://" + folderID + "/yandexgpt/rc",
+		Endpoint:   YandexGPTEndpoint,
+		Options:    Options{Temperature: Float(0.7), MaxTokens: 1024},
 	}
 }
 
 // Complete sends a completion request to the Yandex GPT API
-func (c *YandexClient) Complete(systemMessage, userMessage string) (string, error) {
-	req := Request{
+func (c *YandexClient) Complete(r Request) (*Response, error) {
+	user, err := inlineAttachments("yandex", r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := YandexRequest{
 		ModelURI: c.ModelURI,
-		CompletionOptions: CompletionOptions{
-			MaxTokens:   1024,
-			Temperature: 0.7,
+		CompletionOptions: YandexCompletionOptions{
+			MaxTokens:   c.Options.MaxTokens,
+			Temperature: c.Options.Temperature,
 		},
-		Messages: []Message{
+		Messages: []YandexMessage{
 			{
 				Role: "system",
-				Text: systemMessage,
+				Text: r.System,
 			},
 			{
 				Role: "user",
-				Text: userMessage,
+				Text: user,
 			},
 		},
 	}
 
+	// Yandex only distinguishes between disabled and hidden reasoning
+	if c.Options.ReasoningEffort != "" {
+		req.CompletionOptions.ReasoningOptions = &YandexReasoningOptions{Mode: "ENABLED_HIDDEN"}
+	}
+
 	reqBody, err := json.Marshal(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequest("POST", YandexGPTEndpoint, bytes.NewBuffer(reqBody))
+	httpReq, err := http.NewRequest("POST", c.Endpoint, bytes.NewBuffer(reqBody))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
@@ -107,19 +146,38 @@ func (c *YandexClient) Complete(systemMessage, userMessage string) (string, erro
 
 	resp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var response Response
+	var response YandexResponse
 	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(response.Result.Alternatives) == 0 {
+		return nil, fmt.Errorf("no alternatives in response")
 	}
 
-	return response.Result.Alternatives[0].Message.Text, nil
+	usage := response.Result.Usage
+	return &Response{
+		Text: response.Result.Alternatives[0].Message.Text,
+		Usage: Usage{
+			InputTokens:     parseTokenCount(usage.InputTextTokens),
+			OutputTokens:    parseTokenCount(usage.CompletionTokens),
+			ReasoningTokens: parseTokenCount(usage.CompletionTokensDetails.ReasoningTokens),
+			TotalTokens:     parseTokenCount(usage.TotalTokens),
+		},
+	}, nil
+}
+
+// parseTokenCount parses token counters Yandex reports as strings
+func parseTokenCount(value string) int {
+	count, _ := strconv.Atoi(value)
+	return count
 }
@@ -0,0 +1,59 @@
+package gpt
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestYandexClientComplete(t *testing.T) {
+	newClient := func(baseURL string, httpClient *http.Client) Client {
+		client := NewYandexClient("folder", "token")
+		client.Endpoint = baseURL + "/completion"
+		client.HTTPClient = httpClient
+		return client
+	}
+
+	runClientTests(t, newClient, []clientTest{
+		{
+			name:   "success",
+			status: http.StatusOK,
+			body:   `{"result":{"alternatives":[{"message":{"role":"assistant","text":"hi"},"status":"ALTERNATIVE_STATUS_FINAL"}],"usage":{"inputTextTokens":"20","completionTokens":"2","totalTokens":"22"}}}`,
+			want:   &Response{Text: "hi", Usage: Usage{InputTokens: 20, OutputTokens: 2, TotalTokens: 22}},
+			check: func(t *testing.T, r *http.Request, body map[string]interface{}) {
+				if r.Header.Get("x-folder-id") != "folder" || r.Header.Get("Authorization") != "Bearer token" {
+					t.Errorf("missing auth headers")
+				}
+				if field(body, "modelUri") != "gpt://folder/yandexgpt/rc" {
+					t.Errorf("unexpected model uri %v", field(body, "modelUri"))
+				}
+				if field(body, "completionOptions", "maxTokens") != 1024.0 {
+					t.Errorf("unexpected completion options %v", field(body, "completionOptions"))
+				}
+			},
+		},
+		{
+			name:    "api error",
+			status:  http.StatusUnauthorized,
+			body:    `{"error":{"grpcCode":16,"httpCode":401,"message":"The token is invalid"}}`,
+			wantErr: "The token is invalid",
+		},
+		{
+			name:    "no alternatives",
+			status:  http.StatusOK,
+			body:    `{"result":{"alternatives":[]}}`,
+			wantErr: "no alternatives in response",
+		},
+		{
+			name:    "empty result",
+			status:  http.StatusOK,
+			body:    `{}`,
+			wantErr: "no alternatives in response",
+		},
+		{
+			name:    "malformed json",
+			status:  http.StatusOK,
+			body:    `{"result":`,
+			wantErr: "failed to decode response",
+		},
+	})
+}
@@ -0,0 +1,167 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Event types of JSON output
+const (
+	EventRunStarted      = "run_started"
+	EventStepStarted     = "step_started"
+	EventModelResponse   = "model_response"
+	EventCommandStarted  = "command_started"
+	EventCommandOutput   = "command_output"
+	EventCommandFinished = "command_finished"
+	EventTaskCompleted   = "task_completed"
+	EventRunFailed       = "run_failed"
+	EventLog             = "log"
+	// Prompts are debug events, they are large and repeat every step
+	EventSystemPrompt = "system_prompt"
+	EventPrompt       = "prompt"
+)
+
+// Decisions about a proposed command carried by command_finished
+const (
+	// DecisionAuto is a command run without asking anyone
+	DecisionAuto = "auto"
+	// DecisionApproved is a command the user approved
+	DecisionApproved = "approved"
+	DecisionSkipped  = "skipped"
+	DecisionAborted  = "aborted"
+	// DecisionDenied is a command refused by policy
+	DecisionDenied = "denied"
+	DecisionDryRun = "dry_run"
+)
+
+// Event is a single line of JSON output. Field names are stable, fields
+// not relevant to the event type are omitted
+type Event struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Step int       `json:"step,omitempty"`
+
+	// run_started
+	Task        string `json:"task,omitempty"`
+	Provider    string `json:"provider,omitempty"`
+	Model       string `json:"model,omitempty"`
+	MaxCommands int    `json:"max_commands,omitempty"`
+	DryRun      bool   `json:"dry_run,omitempty"`
+
+	// model_response, command_started and prompts
+	Text      string `json:"text,omitempty"`
+	Thought   string `json:"thought,omitempty"`
+	Command   string `json:"command,omitempty"`
+	Reasoning string `json:"reasoning,omitempty"`
+	Cached    bool   `json:"cached,omitempty"`
+
+	// command_started
+	Risk        string   `json:"risk,omitempty"`
+	RiskReasons []string `json:"risk_reasons,omitempty"`
+
+	// command_output and command_finished
+	Data     string `json:"data,omitempty"`
+	Output   string `json:"output,omitempty"`
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Decision string `json:"decision,omitempty"`
+
+	// task_completed and run_failed
+	Summary string `json:"summary,omitempty"`
+	// Answer is the final answer of read-only runs
+	Answer       string   `json:"answer,omitempty"`
+	FilesChanged []string `json:"files_changed,omitempty"`
+	FollowUps    []string `json:"follow_ups,omitempty"`
+	// OutputData is output the task asked for, any JSON value
+	OutputData json.RawMessage `json:"output_data,omitempty"`
+	Steps      int             `json:"steps,omitempty"`
+
+	// log
+	Level   string `json:"level,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	Error           string `json:"error,omitempty"`
+	InputTokens     int    `json:"input_tokens,omitempty"`
+	OutputTokens    int    `json:"output_tokens,omitempty"`
+	ReasoningTokens int    `json:"reasoning_tokens,omitempty"`
+	TotalTokens     int    `json:"total_tokens,omitempty"`
+	DurationMS      int64  `json:"duration_ms,omitempty"`
+
+	// Duration is exact duration, JSON carries it as DurationMS
+	Duration time.Duration `json:"-"`
+}
+
+// Severity returns level of the event, log events carry their own
+// level, progress of a run is notice
+func (e Event) Severity() Level {
+	switch e.Type {
+	case EventLog:
+		switch e.Level {
+		case "debug":
+			return LevelDebug
+		case "warning":
+			return LevelWarning
+		case "error":
+			return LevelError
+		}
+		return LevelInfo
+	case EventSystemPrompt, EventPrompt:
+		return LevelDebug
+	}
+	return LevelNotice
+}
+
+// Line describes the event in a single line, it is empty for
+// events carrying only details such as output chunks
+func (e Event) Line() string {
+	switch e.Type {
+	case EventLog:
+		return e.Message
+	case EventRunStarted:
+		return fmt.Sprintf("run started with %s: %s", e.Provider, e.Task)
+	case EventCommandStarted:
+		return fmt.Sprintf("step %d: %s", e.Step, e.Command)
+	case EventCommandFinished:
+		if e.Error != "" {
+			return fmt.Sprintf("step %d: command failed: %s", e.Step, e.Error)
+		}
+		return fmt.Sprintf("step %d: command completed", e.Step)
+	case EventTaskCompleted:
+		return fmt.Sprintf("task completed in %d steps: %s", e.Steps, e.Summary)
+	case EventRunFailed:
+		return fmt.Sprintf("run failed after %d steps: %s", e.Steps, e.Error)
+	}
+	return ""
+}
+
+// ModelResponse describes a parsed answer of the model
+type ModelResponse struct {
+	Text            string
+	Thought         string
+	Command         string
+	Reasoning       string
+	InputTokens     int
+	OutputTokens    int
+	ReasoningTokens int
+	Duration        time.Duration
+	Cached          bool
+	// Error is set when the provider call failed
+	Error string
+}
+
+// RunSummary describes outcome of a run
+type RunSummary struct {
+	Completed    bool
+	Summary      string
+	Answer       string
+	FilesChanged []string
+	FollowUps    []string
+	// OutputData is output the task asked for, any JSON value
+	OutputData   json.RawMessage
+	Error        string
+	Steps        int
+	InputTokens  int
+	OutputTokens int
+	TotalTokens  int
+	Duration     time.Duration
+}
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestJSONEvents(t *testing.T) {
+	var buf bytes.Buffer
+	log := NewWithSinks(NewJSON(&buf, LevelInfo))
+
+	log.RunStarted("mock", "", "list files", 5, false)
+	log.StepStarted(1)
+	log.CommandStarted("ls", "look around", "low", nil)
+	fmt.Fprint(log.CommandOutput(), "main.go\n")
+	log.CommandFinished("main.go\n", 0, 0, DecisionAuto, nil)
+	log.Debug("hidden without verbose")
+	log.StepStarted(2)
+	log.CommandFinished("", 1, 0, DecisionAuto, errors.New("exit status 1"))
+	log.RunFinished(RunSummary{Error: "reached maximum number of commands (2)", Steps: 2})
+
+	var events []map[string]interface{}
+	decoder := json.NewDecoder(&buf)
+	for decoder.More() {
+		var event map[string]interface{}
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatalf("invalid event: %v", err)
+		}
+		events = append(events, event)
+	}
+
+	types := []string{
+		EventRunStarted, EventStepStarted, EventCommandStarted, EventCommandOutput,
+		EventCommandFinished, EventStepStarted, EventCommandFinished, EventRunFailed,
+	}
+	if len(events) != len(types) {
+		t.Fatalf("got %d events, want %d: %v", len(events), len(types), events)
+	}
+	for i, want := range types {
+		if events[i]["type"] != want {
+			t.Errorf("event %d is %v, want %s", i, events[i]["type"], want)
+		}
+	}
+
+	if events[3]["data"] != "main.go\n" || events[3]["step"] != 1.0 {
+		t.Errorf("unexpected output event: %v", events[3])
+	}
+	// Zero exit code must not be omitted
+	if code, ok := events[4]["exit_code"]; !ok || code != 0.0 {
+		t.Errorf("unexpected exit code: %v", events[4])
+	}
+	if events[6]["step"] != 2.0 || events[6]["exit_code"] != 1.0 {
+		t.Errorf("unexpected finished event: %v", events[6])
+	}
+	if events[7]["steps"] != 2.0 || events[7]["error"] == nil {
+		t.Errorf("unexpected failure event: %v", events[7])
+	}
+}
+
+func TestCommandOutputKeepsCharactersWhole(t *testing.T) {
+	memory := NewMemory(LevelDebug)
+	log := NewWithSinks(memory)
+
+	output := log.CommandOutput()
+	output.Write([]byte("caf\xc3"))
+	output.Write([]byte("\xa9 \xe2\x82"))
+	output.Write([]byte("\xac"))
+	output.Write([]byte("\xf0\x9f"))
+	output.(interface{ Flush() error }).Flush()
+
+	var chunks []string
+	for _, e := range memory.Events(EventCommandOutput) {
+		chunks = append(chunks, e.Data)
+	}
+	want := []string{"caf", "é ", "€", "\xf0\x9f"}
+	if fmt.Sprint(chunks) != fmt.Sprint(want) {
+		t.Fatalf("chunks = %q, want %q", chunks, want)
+	}
+}
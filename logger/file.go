@@ -0,0 +1,226 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default rotation limits of log files
+const (
+	DefaultMaxSize  = 10 * 1024 * 1024
+	DefaultMaxFiles = 5
+)
+
+// FileLog writes uncoloured timestamped records to a file. Once the file
+// grows over MaxSize it is renamed to path.1, older files shift up to
+// path.MaxFiles and the oldest one is removed
+type FileLog struct {
+	MaxSize  int64
+	MaxFiles int
+	// MinLevel filters events, the default keeps everything
+	MinLevel Level
+	// JSON writes events as NDJSON instead of text records
+	JSON bool
+
+	mu   sync.Mutex
+	path string
+	file *os.File
+	size int64
+}
+
+// OpenFileLog opens log file for appending, creating parent directories
+func OpenFileLog(path string) (*FileLog, error) {
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+
+	f := &FileLog{MaxSize: DefaultMaxSize, MaxFiles: DefaultMaxFiles, path: path}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileLog) open() error {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	return nil
+}
+
+// Record writes an entry with key value pairs in fields, every line
+// of body is written indented below the header line
+func (f *FileLog) Record(event, body string, fields ...interface{}) {
+	var b strings.Builder
+	b.WriteString(time.Now().Format("2006-01-02T15:04:05.000Z07:00"))
+	b.WriteString(" ")
+	b.WriteString(event)
+	for i := 0; i+1 < len(fields); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", fields[i], formatValue(fields[i+1]))
+	}
+	b.WriteString("\n")
+	if body = strings.TrimRight(body, "\n"); body != "" {
+		for _, line := range strings.Split(body, "\n") {
+			b.WriteString("    ")
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	f.write(b.String())
+}
+
+func (f *FileLog) Level() Level {
+	return f.MinLevel
+}
+
+// Write records an event, output chunks are skipped since the
+// finished command carries complete output
+func (f *FileLog) Write(e Event) {
+	if f.JSON {
+		data, err := json.Marshal(e)
+		if err == nil {
+			f.write(string(data) + "\n")
+		}
+		return
+	}
+
+	switch e.Type {
+	case EventLog:
+		f.Record(e.Level, e.Message)
+	case EventRunStarted:
+		f.Record(e.Type, e.Task, "provider", e.Provider, "max_commands", e.MaxCommands, "dry_run", e.DryRun)
+	case EventStepStarted:
+		f.Record(e.Type, "", "step", e.Step)
+	case EventSystemPrompt:
+		f.Record(e.Type, e.Text)
+	case EventPrompt:
+		f.Record(e.Type, e.Text, "step", e.Step)
+	case EventModelResponse:
+		if e.Error != "" {
+			f.Record(e.Type, e.Error, "step", e.Step, "duration", e.Duration, "error", true)
+			return
+		}
+		f.Record(e.Type, e.Text, "step", e.Step, "duration", e.Duration,
+			"input_tokens", e.InputTokens, "output_tokens", e.OutputTokens,
+			"reasoning_tokens", e.ReasoningTokens, "cached", e.Cached)
+		if e.Reasoning != "" {
+			f.Record("reasoning", e.Reasoning, "step", e.Step)
+		}
+	case EventCommandStarted:
+		f.Record(e.Type, e.Command, "step", e.Step, "thought", e.Thought, "risk", e.Risk, "risk_reasons", strings.Join(e.RiskReasons, "; "))
+	case EventCommandFinished:
+		code := -1
+		if e.ExitCode != nil {
+			code = *e.ExitCode
+		}
+		f.Record(e.Type, e.Output, "step", e.Step, "exit_code", code, "decision", e.Decision, "duration", e.Duration, "error", e.Error)
+	case EventTaskCompleted, EventRunFailed:
+		body := strings.TrimSpace(e.Summary + "\n\n" + e.Answer)
+		if e.Type == EventRunFailed {
+			body = e.Error
+		}
+		fields := []interface{}{"steps", e.Steps, "input_tokens", e.InputTokens,
+			"output_tokens", e.OutputTokens, "total_tokens", e.TotalTokens, "duration", e.Duration}
+		if len(e.FilesChanged) > 0 {
+			fields = append(fields, "files_changed", strings.Join(e.FilesChanged, ","))
+		}
+		if len(e.FollowUps) > 0 {
+			fields = append(fields, "follow_ups", strings.Join(e.FollowUps, "; "))
+		}
+		if len(e.OutputData) > 0 {
+			fields = append(fields, "output_data", string(e.OutputData))
+		}
+		f.Record(e.Type, body, fields...)
+	}
+}
+
+func (f *FileLog) write(s string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return
+	}
+	if f.MaxSize > 0 && f.size > 0 && f.size+int64(len(s)) > f.MaxSize {
+		f.rotate()
+	}
+	n, _ := f.file.WriteString(s)
+	f.size += int64(n)
+}
+
+// rotate shifts old files up and starts a new one, failures leave
+// logging to the current file
+func (f *FileLog) rotate() {
+	if f.MaxFiles <= 0 {
+		// Without rotated files the current one starts over
+		if err := f.file.Truncate(0); err == nil {
+			f.size = 0
+		}
+		return
+	}
+
+	// The current file stays open while it is renamed, so it is still
+	// there to write to when a new one can't be opened
+	current := f.file
+	os.Remove(fmt.Sprintf("%s.%d", f.path, f.MaxFiles))
+	for i := f.MaxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", f.path, i), fmt.Sprintf("%s.%d", f.path, i+1))
+	}
+	os.Rename(f.path, f.path+".1")
+
+	if err := f.open(); err != nil {
+		// Move the current file back and try again after another MaxSize
+		// bytes instead of shifting files on every write
+		os.Rename(f.path+".1", f.path)
+		f.size = 0
+		return
+	}
+	current.Close()
+}
+
+// Close closes the underlying file
+func (f *FileLog) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+func formatValue(value interface{}) string {
+	var s string
+	switch v := value.(type) {
+	case time.Duration:
+		s = v.Round(time.Millisecond).String()
+	case error:
+		s = v.Error()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFileLogRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "g8t.log")
+	file, err := OpenFileLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := NewWithWriter(false, true, &strings.Builder{})
+	log.AddSink(file)
+	log.Info("quiet terminal, still logged")
+	file.Record("command_finished", "line one\nline two\n", "step", 1, "error", "exit status 1")
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern := `^\S+ info\n    quiet terminal, still logged\n` +
+		`\S+ command_finished step=1 error="exit status 1"\n    line one\n    line two\n$`
+	if !regexp.MustCompile(pattern).Match(data) {
+		t.Fatalf("unexpected log:\n%s", data)
+	}
+}
+
+func TestFileLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "g8t.log")
+	file, err := OpenFileLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.MaxSize = 100
+	file.MaxFiles = 2
+
+	for i := 0; i < 10; i++ {
+		file.Record("info", strings.Repeat("x", 60))
+	}
+	file.Close()
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s is over the size limit: %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Error("expected only MaxFiles rotated files to be kept")
+	}
+}
+
+func TestFileLogRotationWithoutFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "g8t.log")
+	file, err := OpenFileLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.MaxSize = 100
+	file.MaxFiles = 0
+
+	for i := 0; i < 10; i++ {
+		file.Record("info", strings.Repeat("x", 60))
+	}
+	file.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil || len(data) == 0 || len(data) > 100 {
+		t.Fatalf("expected the file to start over, got %d bytes, %v", len(data), err)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("expected no rotated files")
+	}
+}
@@ -0,0 +1,25 @@
+package logger
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSON writes events as NDJSON, one object per line
+type JSON struct {
+	encoder *json.Encoder
+	level   Level
+}
+
+// NewJSON creates sink writing events to w
+func NewJSON(w io.Writer, level Level) *JSON {
+	return &JSON{encoder: json.NewEncoder(w), level: level}
+}
+
+func (j *JSON) Level() Level {
+	return j.level
+}
+
+func (j *JSON) Write(e Event) {
+	j.encoder.Encode(e)
+}
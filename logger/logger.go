@@ -2,95 +2,283 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"sync"
 	"time"
-
-	"github.com/fatih/color"
+	"unicode/utf8"
 )
 
-type Logger struct {
-	verbose bool
-	quiet   bool
+// Logger reports progress of a run, every call becomes an Event
+// delivered to sinks
+type Logger interface {
+	Debug(msg string, args ...interface{})
+	Info(msg string, args ...interface{})
+	Success(msg string, args ...interface{})
+	Warning(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+
+	RunStarted(provider, model, task string, maxCommands int, dryRun bool)
+	StepStarted(step int)
+	SystemPrompt(prompt string)
+	Prompt(prompt string)
+	ModelResponse(r ModelResponse)
+	// CommandStarted reports a proposed command with its risk level and
+	// reasons for it
+	CommandStarted(command, thought, risk string, reasons []string)
+	// CommandOutput returns writer streaming output of a running command,
+	// a Flush method, when it has one, writes output held back
+	CommandOutput() io.Writer
+	// CommandFinished reports result of a command and the decision
+	// whether to run it, code is -1 for commands not executed
+	CommandFinished(output string, code int, duration time.Duration, decision string, err error)
+	RunFinished(s RunSummary)
+}
+
+// Sink receives events of a logger
+type Sink interface {
+	// Level is the least severe level the sink accepts
+	Level() Level
+	Write(e Event)
 }
 
-func New(verbose, quiet bool) *Logger {
-	return &Logger{
-		verbose: verbose,
-		quiet:   quiet,
+// Level is severity of an event
+type Level int
+
+// Levels from the most verbose to the most severe. Notice covers
+// progress of a run, so quiet output keeps steps and commands
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelNotice
+	LevelWarning
+	LevelError
+)
+
+var levelNames = []string{"debug", "info", "notice", "warning", "error"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
 	}
+	return levelNames[l]
 }
 
-func (l *Logger) Info(msg string, args ...interface{}) {
-	if l.quiet {
-		return
+// ParseLevel parses level name
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), nil
+		}
 	}
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("🔵 %s %s\n", color.CyanString(timestamp), fmt.Sprintf(msg, args...))
+	return 0, fmt.Errorf("unknown log level: %s", name)
 }
 
-func (l *Logger) Success(msg string, args ...interface{}) {
-	if l.quiet {
-		return
+// ConsoleLevel returns level of terminal output for verbose and quiet modes
+func ConsoleLevel(verbose, quiet bool) Level {
+	switch {
+	case verbose:
+		return LevelDebug
+	case quiet:
+		return LevelNotice
 	}
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("✅ %s %s\n", color.GreenString(timestamp), fmt.Sprintf(msg, args...))
+	return LevelInfo
+}
+
+// Dispatcher is a Logger delivering events to every sink accepting
+// their level
+type Dispatcher struct {
+	mu    sync.Mutex
+	sinks []Sink
+	step  int
+}
+
+// New creates logger printing to the terminal
+func New(verbose, quiet bool) *Dispatcher {
+	return NewWithSinks(NewTerminal(os.Stdout, os.Stderr, ConsoleLevel(verbose, quiet)))
 }
 
-func (l *Logger) Warning(msg string, args ...interface{}) {
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("⚠️  %s %s\n", color.YellowString(timestamp), fmt.Sprintf(msg, args...))
+// NewWithWriter creates logger writing all output including errors to w
+func NewWithWriter(verbose, quiet bool, w io.Writer) *Dispatcher {
+	return NewWithSinks(NewTerminal(w, w, ConsoleLevel(verbose, quiet)))
 }
 
-func (l *Logger) Error(msg string, args ...interface{}) {
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Fprintf(os.Stderr, "❌ %s %s\n", color.RedString(timestamp), fmt.Sprintf(msg, args...))
+// NewWithSinks creates logger delivering events to sinks
+func NewWithSinks(sinks ...Sink) *Dispatcher {
+	return &Dispatcher{sinks: sinks}
 }
 
-func (l *Logger) Debug(msg string, args ...interface{}) {
-	if !l.verbose {
-		return
+// AddSink adds sink receiving further events
+func (d *Dispatcher) AddSink(s Sink) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.sinks = append(d.sinks, s)
+}
+
+// Close closes sinks holding files or connections
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var first error
+	for _, s := range d.sinks {
+		if closer, ok := s.(io.Closer); ok {
+			if err := closer.Close(); err != nil && first == nil {
+				first = err
+			}
+		}
 	}
-	timestamp := time.Now().Format("15:04:05")
-	fmt.Printf("🔍 %s %s\n", color.MagentaString(timestamp), fmt.Sprintf(msg, args...))
+	return first
 }
 
-func (l *Logger) StartAgent(provider string, task string, maxCommands int, dryRun bool) {
-	fmt.Printf("\n🚀 Starting AI Agent\n")
-	fmt.Printf("   Provider: %s\n", color.CyanString(provider))
-	fmt.Printf("   Task: %s\n", color.WhiteString(task))
-	fmt.Printf("   Max Commands: %s\n", color.YellowString("%d", maxCommands))
-	if dryRun {
-		fmt.Printf("   Mode: %s\n", color.MagentaString("DRY RUN"))
+func (d *Dispatcher) emit(e Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	e.Time = time.Now()
+	if e.Duration > 0 {
+		e.DurationMS = e.Duration.Milliseconds()
 	}
-	fmt.Println()
+	level := e.Severity()
+	for _, s := range d.sinks {
+		if level >= s.Level() {
+			s.Write(e)
+		}
+	}
+}
+
+func (d *Dispatcher) log(level, msg string, args []interface{}) {
+	d.emit(Event{Type: EventLog, Step: d.step, Level: level, Message: fmt.Sprintf(msg, args...)})
+}
+
+func (d *Dispatcher) Debug(msg string, args ...interface{}) {
+	d.log("debug", msg, args)
+}
+
+func (d *Dispatcher) Info(msg string, args ...interface{}) {
+	d.log("info", msg, args)
+}
+
+func (d *Dispatcher) Success(msg string, args ...interface{}) {
+	d.log("success", msg, args)
+}
+
+func (d *Dispatcher) Warning(msg string, args ...interface{}) {
+	d.log("warning", msg, args)
+}
+
+func (d *Dispatcher) Error(msg string, args ...interface{}) {
+	d.log("error", msg, args)
+}
+
+func (d *Dispatcher) RunStarted(provider, model, task string, maxCommands int, dryRun bool) {
+	d.emit(Event{Type: EventRunStarted, Task: task, Provider: provider, Model: model, MaxCommands: maxCommands, DryRun: dryRun})
+}
+
+func (d *Dispatcher) StepStarted(step int) {
+	d.step = step
+	d.emit(Event{Type: EventStepStarted, Step: step})
 }
 
-func (l *Logger) StartStep(step int) {
-	fmt.Printf("⚙️  Step %s\n", color.CyanString("%d", step))
+func (d *Dispatcher) SystemPrompt(prompt string) {
+	d.emit(Event{Type: EventSystemPrompt, Text: prompt})
 }
 
-func (l *Logger) ExecuteCommand(command, thought string) {
-	fmt.Printf("🔧 %s\n", color.WhiteString(command))
-	if l.verbose && thought != "" {
-		fmt.Printf("   💭 %s\n", color.HiBlackString(thought))
+func (d *Dispatcher) Prompt(prompt string) {
+	d.emit(Event{Type: EventPrompt, Step: d.step, Text: prompt})
+}
+
+func (d *Dispatcher) ModelResponse(r ModelResponse) {
+	d.emit(Event{
+		Type:            EventModelResponse,
+		Step:            d.step,
+		Text:            r.Text,
+		Thought:         r.Thought,
+		Command:         r.Command,
+		Reasoning:       r.Reasoning,
+		Cached:          r.Cached,
+		InputTokens:     r.InputTokens,
+		OutputTokens:    r.OutputTokens,
+		ReasoningTokens: r.ReasoningTokens,
+		Duration:        r.Duration,
+		Error:           r.Error,
+	})
+}
+
+func (d *Dispatcher) CommandStarted(command, thought, risk string, reasons []string) {
+	d.emit(Event{Type: EventCommandStarted, Step: d.step, Command: command, Thought: thought, Risk: risk, RiskReasons: reasons})
+}
+
+func (d *Dispatcher) CommandOutput() io.Writer {
+	return &outputWriter{d: d}
+}
+
+// outputWriter holds back a character split between writes, so every
+// chunk of Data is valid UTF-8
+type outputWriter struct {
+	d       *Dispatcher
+	pending []byte
+}
+
+func (w *outputWriter) Write(p []byte) (int, error) {
+	data := append(w.pending, p...)
+	end := len(data)
+	// A rune is at most 4 bytes, look for an unfinished one at the end
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				end = i
+			}
+			break
+		}
+	}
+	w.pending = append([]byte(nil), data[end:]...)
+	if end > 0 {
+		w.d.emit(Event{Type: EventCommandOutput, Step: w.d.step, Data: string(data[:end])})
 	}
+	return len(p), nil
 }
 
-func (l *Logger) CommandSuccess(output string) {
-	if output != "" && l.verbose {
-		fmt.Printf("   📤 %s\n", color.GreenString(output))
+// Flush writes output held back, called when the command exits
+func (w *outputWriter) Flush() error {
+	if len(w.pending) > 0 {
+		w.d.emit(Event{Type: EventCommandOutput, Step: w.d.step, Data: string(w.pending)})
+		w.pending = nil
 	}
-	fmt.Printf("✅ Command completed\n\n")
+	return nil
 }
 
-func (l *Logger) CommandError(err error) {
-	fmt.Printf("❌ Command failed: %s\n\n", color.RedString(err.Error()))
+func (d *Dispatcher) CommandFinished(output string, code int, duration time.Duration, decision string, err error) {
+	e := Event{
+		Type:     EventCommandFinished,
+		Step:     d.step,
+		Output:   output,
+		ExitCode: &code,
+		Decision: decision,
+		Duration: duration,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	d.emit(e)
 }
 
-func (l *Logger) TaskCompleted(thought string) {
-	fmt.Printf("🎉 %s\n", color.GreenString("Task completed successfully!"))
-	if thought != "" && l.verbose {
-		fmt.Printf("   💭 %s\n", color.HiBlackString(thought))
+func (d *Dispatcher) RunFinished(s RunSummary) {
+	e := Event{
+		Type:         EventTaskCompleted,
+		Summary:      s.Summary,
+		Answer:       s.Answer,
+		FilesChanged: s.FilesChanged,
+		FollowUps:    s.FollowUps,
+		OutputData:   s.OutputData,
+		Error:        s.Error,
+		Steps:        s.Steps,
+		InputTokens:  s.InputTokens,
+		OutputTokens: s.OutputTokens,
+		TotalTokens:  s.TotalTokens,
+		Duration:     s.Duration,
+	}
+	if !s.Completed {
+		e.Type = EventRunFailed
 	}
-	fmt.Println()
+	d.emit(e)
 }
@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSinkLevels(t *testing.T) {
+	verbose := NewMemory(LevelDebug)
+	quiet := NewMemory(LevelNotice)
+	errors := NewMemory(LevelError)
+	log := NewWithSinks(verbose, quiet, errors)
+
+	log.Debug("debug")
+	log.Info("info")
+	log.StepStarted(1)
+	log.Prompt("what next?")
+	log.Warning("warning")
+	log.Error("error")
+
+	for _, tc := range []struct {
+		sink *Memory
+		want int
+	}{
+		{verbose, 6},
+		{quiet, 3},
+		{errors, 1},
+	} {
+		if got := len(tc.sink.Events()); got != tc.want {
+			t.Errorf("sink of level %s got %d events, want %d", tc.sink.Level(), got, tc.want)
+		}
+	}
+
+	prompts := verbose.Events(EventPrompt)
+	if len(prompts) != 1 || prompts[0].Step != 1 || prompts[0].Text != "what next?" {
+		t.Errorf("unexpected prompt events: %v", prompts)
+	}
+}
+
+func TestPlainOutput(t *testing.T) {
+	var out strings.Builder
+	log := NewWithWriter(true, false, &out)
+
+	log.RunStarted("mock", "", "list files", 5, false)
+	log.StepStarted(1)
+	log.ModelResponse(ModelResponse{Reasoning: "need a listing"})
+	log.CommandStarted("ls", "look around", "low", nil)
+	log.CommandFinished("main.go", 0, 0, DecisionAuto, nil)
+	log.RunFinished(RunSummary{Completed: true, Summary: "listed"})
+
+	want := "\nStarting AI Agent\n" +
+		"   Provider: mock\n" +
+		"   Task: list files\n" +
+		"   Max Commands: 5\n\n" +
+		"Step 1\n" +
+		"$ ls\n" +
+		"   reasoning: need a listing\n" +
+		"   thought: look around\n" +
+		"   output: main.go\n" +
+		"Command completed\n\n" +
+		"Task completed successfully!\n" +
+		"   summary: listed\n\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
+
+func TestAnswerOutput(t *testing.T) {
+	var out, answer strings.Builder
+	text := NewPlain(&out, &out, LevelNotice)
+	log := NewWithSinks(text)
+	log.RunFinished(RunSummary{Completed: true, Summary: "found it", Answer: "nginx listens on 8080\n\n"})
+	if want := "Task completed successfully!\n   summary: found it\n\nAnswer:\nnginx listens on 8080\n\n"; out.String() != want {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+
+	out.Reset()
+	text.AnswerOut = &answer
+	log.RunFinished(RunSummary{Completed: true, Answer: "nginx listens on 8080"})
+	if answer.String() != "nginx listens on 8080\n" || strings.Contains(out.String(), "8080") {
+		t.Errorf("answer not separated from progress: %q and %q", answer.String(), out.String())
+	}
+}
+
+func TestResultOutput(t *testing.T) {
+	var out strings.Builder
+	log := NewWithSinks(NewPlain(&out, &out, LevelNotice))
+	log.RunFinished(RunSummary{
+		Completed:    true,
+		Summary:      "added a health check",
+		FilesChanged: []string{"main.go", "main_test.go"},
+		FollowUps:    []string{"deploy it", "add an alert"},
+		OutputData:   []byte("{\n  \"port\": 8080\n}"),
+	})
+
+	want := "Task completed successfully!\n" +
+		"   summary: added a health check\n" +
+		"   files changed: main.go, main_test.go\n" +
+		"   follow-up: deploy it\n" +
+		"   follow-up: add an alert\n" +
+		"   data: {\"port\":8080}\n\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%q\nwant:\n%q", out.String(), want)
+	}
+}
@@ -0,0 +1,47 @@
+package logger
+
+import "sync"
+
+// Memory keeps events in memory, it is meant for tests
+type Memory struct {
+	mu     sync.Mutex
+	level  Level
+	events []Event
+}
+
+// NewMemory creates sink keeping events of level and above
+func NewMemory(level Level) *Memory {
+	return &Memory{level: level}
+}
+
+func (m *Memory) Level() Level {
+	return m.level
+}
+
+func (m *Memory) Write(e Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, e)
+}
+
+// Events returns received events, optionally only those of given types
+func (m *Memory) Events(types ...string) []Event {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var events []Event
+	for _, e := range m.events {
+		if len(types) == 0 || contains(types, e.Type) {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
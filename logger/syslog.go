@@ -0,0 +1,52 @@
+//go:build !windows && !plan9
+
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+)
+
+// Syslog sends single line summaries of events to the system log,
+// journald collects them as well
+type Syslog struct {
+	writer *syslog.Writer
+	level  Level
+}
+
+// NewSyslog connects to the local syslog daemon
+func NewSyslog(tag string, level Level) (Sink, error) {
+	writer, err := syslog.New(syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to syslog: %w", err)
+	}
+	return &Syslog{writer: writer, level: level}, nil
+}
+
+func (s *Syslog) Level() Level {
+	return s.level
+}
+
+func (s *Syslog) Write(e Event) {
+	line := e.Line()
+	if line == "" {
+		return
+	}
+	switch e.Severity() {
+	case LevelDebug:
+		s.writer.Debug(line)
+	case LevelInfo:
+		s.writer.Info(line)
+	case LevelNotice:
+		s.writer.Notice(line)
+	case LevelWarning:
+		s.writer.Warning(line)
+	default:
+		s.writer.Err(line)
+	}
+}
+
+// Close closes connection to syslog
+func (s *Syslog) Close() error {
+	return s.writer.Close()
+}
@@ -0,0 +1,10 @@
+//go:build windows || plan9
+
+package logger
+
+import "fmt"
+
+// NewSyslog fails, syslog is not available on this platform
+func NewSyslog(tag string, level Level) (Sink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
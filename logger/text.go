@@ -0,0 +1,242 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+)
+
+// Text prints events for people, pretty output uses colours and emoji,
+// plain output suits pipes and files
+type Text struct {
+	out    io.Writer
+	errOut io.Writer
+	level  Level
+	plain  bool
+	// AnswerOut receives final answers verbatim instead of out, so
+	// progress and the answer can go to different places
+	AnswerOut io.Writer
+
+	dryRun    bool
+	reasoning string
+}
+
+// NewPretty creates sink printing coloured output with emoji, errors
+// go to errOut
+func NewPretty(out, errOut io.Writer, level Level) *Text {
+	return &Text{out: out, errOut: errOut, level: level}
+}
+
+// NewPlain creates sink printing output without colours and emoji
+func NewPlain(out, errOut io.Writer, level Level) *Text {
+	return &Text{out: out, errOut: errOut, level: level, plain: true}
+}
+
+// NewTerminal creates pretty sink when out is a terminal and plain
+// sink otherwise
+func NewTerminal(out, errOut io.Writer, level Level) *Text {
+	if IsTerminal(out) {
+		return NewPretty(out, errOut, level)
+	}
+	return NewPlain(out, errOut, level)
+}
+
+// IsTerminal reports whether w is a terminal
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && (isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd()))
+}
+
+var prettySymbols = map[string]string{
+	"debug":     "🔍 ",
+	"info":      "🔵 ",
+	"success":   "✅ ",
+	"warning":   "⚠️  ",
+	"error":     "❌ ",
+	"start":     "\n🚀 ",
+	"step":      "⚙️  ",
+	"command":   "🔧 ",
+	"risk":      "   ⚠️  ",
+	"thought":   "   💭 ",
+	"reasoning": "   🧠 ",
+	"output":    "   📤 ",
+	"summary":   "   📝 ",
+	"files":     "   📁 ",
+	"follow_up": "   👉 ",
+	"data":      "   📦 ",
+	"answer":    "💬 ",
+	"completed": "✅ ",
+	"failed":    "❌ ",
+	"done":      "🎉 ",
+}
+
+var plainSymbols = map[string]string{
+	"debug":     "DEBUG ",
+	"info":      "INFO ",
+	"success":   "OK ",
+	"warning":   "WARN ",
+	"error":     "ERROR ",
+	"start":     "\n",
+	"step":      "",
+	"command":   "$ ",
+	"risk":      "   ",
+	"thought":   "   thought: ",
+	"reasoning": "   reasoning: ",
+	"output":    "   output: ",
+	"summary":   "   summary: ",
+	"files":     "   files changed: ",
+	"follow_up": "   follow-up: ",
+	"data":      "   data: ",
+	"answer":    "",
+	"completed": "",
+	"failed":    "",
+	"done":      "",
+}
+
+func (t *Text) Level() Level {
+	return t.level
+}
+
+func (t *Text) Write(e Event) {
+	verbose := t.level <= LevelDebug
+
+	switch e.Type {
+	case EventLog:
+		out := t.out
+		if e.Level == "error" {
+			out = t.errOut
+		}
+		colors := map[string]func(string, ...interface{}) string{
+			"debug":   color.MagentaString,
+			"info":    color.CyanString,
+			"success": color.GreenString,
+			"warning": color.YellowString,
+			"error":   color.RedString,
+		}
+		timestamp := e.Time.Format("15:04:05")
+		fmt.Fprintf(out, "%s%s %s\n", t.symbol(e.Level), t.color(colors[e.Level], timestamp), e.Message)
+
+	case EventRunStarted:
+		t.dryRun = e.DryRun
+		fmt.Fprintf(t.out, "%sStarting AI Agent\n", t.symbol("start"))
+		fmt.Fprintf(t.out, "   Provider: %s\n", t.color(color.CyanString, e.Provider))
+		fmt.Fprintf(t.out, "   Task: %s\n", t.color(color.WhiteString, e.Task))
+		fmt.Fprintf(t.out, "   Max Commands: %s\n", t.color(color.YellowString, fmt.Sprint(e.MaxCommands)))
+		if e.DryRun {
+			fmt.Fprintf(t.out, "   Mode: %s\n", t.color(color.MagentaString, "DRY RUN"))
+		}
+		fmt.Fprintln(t.out)
+
+	case EventStepStarted:
+		fmt.Fprintf(t.out, "%sStep %s\n", t.symbol("step"), t.color(color.CyanString, fmt.Sprint(e.Step)))
+
+	case EventModelResponse:
+		// Reasoning is shown below the command or the final message
+		t.reasoning = e.Reasoning
+
+	case EventCommandStarted:
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("command"), t.color(color.WhiteString, e.Command))
+		if e.Risk != "" && e.Risk != "low" {
+			colorFn := color.RedString
+			if e.Risk == "medium" {
+				colorFn = color.YellowString
+			}
+			fmt.Fprintf(t.out, "%s%s\n", t.symbol("risk"), t.color(colorFn, strings.ToUpper(e.Risk)+" risk: "+strings.Join(e.RiskReasons, "; ")))
+		}
+		t.details(verbose, e.Thought)
+
+	case EventCommandFinished:
+		// Dry run is reported by the agent, nothing was executed
+		if t.dryRun {
+			return
+		}
+		if e.Error != "" {
+			fmt.Fprintf(t.out, "%sCommand failed: %s\n\n", t.symbol("failed"), t.color(color.RedString, e.Error))
+			return
+		}
+		if verbose && e.Output != "" {
+			fmt.Fprintf(t.out, "%s%s\n", t.symbol("output"), t.color(color.GreenString, e.Output))
+		}
+		fmt.Fprintf(t.out, "%sCommand completed\n\n", t.symbol("completed"))
+
+	case EventTaskCompleted:
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("done"), t.color(color.GreenString, "Task completed successfully!"))
+		t.details(verbose, "")
+		t.result(e)
+		fmt.Fprintln(t.out)
+		if e.Answer != "" {
+			t.answer(e.Answer)
+		}
+	}
+}
+
+// result prints what the model reported on completion, unlike thoughts
+// it is shown at every level
+func (t *Text) result(e Event) {
+	if e.Summary != "" {
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("summary"), e.Summary)
+	}
+	if len(e.FilesChanged) > 0 {
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("files"), t.color(color.CyanString, strings.Join(e.FilesChanged, ", ")))
+	}
+	for _, followUp := range e.FollowUps {
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("follow_up"), followUp)
+	}
+	if len(e.OutputData) > 0 {
+		var data bytes.Buffer
+		if err := json.Compact(&data, e.OutputData); err != nil {
+			data.Reset()
+			data.Write(e.OutputData)
+		}
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("data"), data.String())
+	}
+}
+
+// answer prints final answer of a read-only run
+func (t *Text) answer(answer string) {
+	answer = strings.TrimRight(answer, "\n") + "\n"
+	if t.AnswerOut != nil {
+		io.WriteString(t.AnswerOut, answer)
+		return
+	}
+	fmt.Fprintf(t.out, "%s%s\n", t.symbol("answer"), t.color(color.CyanString, "Answer:"))
+	fmt.Fprintln(t.out, answer)
+}
+
+// details prints reasoning of the last response and the thought in
+// verbose mode
+func (t *Text) details(verbose bool, thought string) {
+	reasoning := t.reasoning
+	t.reasoning = ""
+	if !verbose {
+		return
+	}
+	if reasoning != "" {
+		for _, line := range strings.Split(strings.TrimSpace(reasoning), "\n") {
+			fmt.Fprintf(t.out, "%s%s\n", t.symbol("reasoning"), t.color(color.HiBlackString, line))
+		}
+	}
+	if thought != "" {
+		fmt.Fprintf(t.out, "%s%s\n", t.symbol("thought"), t.color(color.HiBlackString, thought))
+	}
+}
+
+func (t *Text) symbol(name string) string {
+	if t.plain {
+		return plainSymbols[name]
+	}
+	return prettySymbols[name]
+}
+
+func (t *Text) color(fn func(string, ...interface{}) string, s string) string {
+	if t.plain {
+		return s
+	}
+	return fn("%s", s)
+}
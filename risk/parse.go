@@ -0,0 +1,316 @@
+package risk
+
+import (
+	"strings"
+	"unicode"
+)
+
+// simpleCommand is a command with its arguments and redirections, words
+// keep quotes removed and substitutions in place
+type simpleCommand struct {
+	args      []string
+	redirects []redirect
+	// subs are scripts of command and process substitutions
+	subs []string
+	// piped is set when output of the previous command is piped in
+	piped bool
+}
+
+type redirect struct {
+	op     string
+	target string
+}
+
+// parser splits a shell script into simple commands. It is not a full
+// shell parser, it understands enough of the syntax to tell commands,
+// arguments and redirections apart
+type parser struct {
+	input []rune
+	pos   int
+
+	commands []simpleCommand
+	current  simpleCommand
+	word     strings.Builder
+	inWord   bool
+	// pending is redirection operator waiting for its target
+	pending string
+	// heredocs are delimiters of bodies starting at the next line
+	heredocs []heredoc
+}
+
+type heredoc struct {
+	delimiter string
+	stripTabs bool
+}
+
+// parse returns simple commands of script in order of appearance
+func parse(script string) []simpleCommand {
+	p := &parser{input: []rune(script)}
+	p.run()
+	return p.commands
+}
+
+func (p *parser) run() {
+	for p.pos < len(p.input) {
+		r := p.input[p.pos]
+		switch {
+		case r == '\n':
+			p.endCommand(false)
+			p.pos++
+			p.skipHeredocs()
+		case r == ' ' || r == '\t' || r == '\r':
+			p.endWord()
+			p.pos++
+		case r == '#' && !p.inWord:
+			for p.pos < len(p.input) && p.input[p.pos] != '\n' {
+				p.pos++
+			}
+		case r == '\\':
+			if p.pos+1 < len(p.input) && p.input[p.pos+1] == '\n' {
+				p.pos += 2
+				continue
+			}
+			if p.pos+1 < len(p.input) {
+				p.add(p.input[p.pos+1])
+			}
+			p.pos += 2
+		case r == '\'':
+			p.inWord = true
+			end := p.find('\'', p.pos+1)
+			p.word.WriteString(string(p.input[p.pos+1 : end]))
+			p.pos = end + 1
+		case r == '"':
+			p.doubleQuoted()
+		case r == '`':
+			end := p.find('`', p.pos+1)
+			p.substitution(p.pos, p.pos+1, end, end+1)
+		case r == '$' && p.next("$("):
+			end := p.closing(p.pos + 2)
+			p.substitution(p.pos, p.pos+2, end, end+1)
+		case (r == '<' || r == '>') && p.next(string(r)+"(") && !p.inWord:
+			end := p.closing(p.pos + 2)
+			p.endWord()
+			p.current.subs = append(p.current.subs, string(p.input[p.pos+2:end]))
+			p.current.args = append(p.current.args, string(p.input[p.pos:min(end+1, len(p.input))]))
+			p.pos = end + 1
+		case r == '|':
+			switch {
+			case p.next("||"):
+				p.endCommand(false)
+				p.pos += 2
+			case p.next("|&"):
+				p.endCommand(true)
+				p.pos += 2
+			default:
+				p.endCommand(true)
+				p.pos++
+			}
+		case r == '&':
+			switch {
+			case p.next("&&"):
+				p.endCommand(false)
+				p.pos += 2
+			case p.next("&>>"):
+				p.redirect("&>>")
+			case p.next("&>"):
+				p.redirect("&>")
+			default:
+				p.endCommand(false)
+				p.pos++
+			}
+		case r == ';' || r == '(' || r == ')':
+			p.endCommand(false)
+			p.pos++
+		case r == '<' || r == '>':
+			p.redirect(p.operator())
+		default:
+			p.add(r)
+			p.pos++
+		}
+	}
+	p.endCommand(false)
+}
+
+// operator reads redirection operator at the current position, fd
+// numbers written right before it are dropped from the word
+func (p *parser) operator() string {
+	for _, op := range []string{"<<<", "<<-", "<<", "<>", ">>", ">|", ">&", "<&", ">", "<"} {
+		if p.next(op) {
+			if p.inWord && isNumber(p.word.String()) {
+				p.word.Reset()
+				p.inWord = false
+			}
+			return op
+		}
+	}
+	return string(p.input[p.pos])
+}
+
+func (p *parser) redirect(op string) {
+	p.endWord()
+	p.pending = op
+	p.pos += len([]rune(op))
+}
+
+func (p *parser) add(r rune) {
+	p.inWord = true
+	p.word.WriteRune(r)
+}
+
+// doubleQuoted reads a double-quoted string, substitutions inside it
+// still run
+func (p *parser) doubleQuoted() {
+	p.inWord = true
+	p.pos++
+	for p.pos < len(p.input) && p.input[p.pos] != '"' {
+		switch {
+		case p.input[p.pos] == '\\' && p.pos+1 < len(p.input):
+			p.word.WriteRune(p.input[p.pos+1])
+			p.pos += 2
+		case p.next("$("):
+			end := p.closing(p.pos + 2)
+			p.substitution(p.pos, p.pos+2, end, end+1)
+		case p.input[p.pos] == '`':
+			end := p.find('`', p.pos+1)
+			p.substitution(p.pos, p.pos+1, end, end+1)
+		default:
+			p.word.WriteRune(p.input[p.pos])
+			p.pos++
+		}
+	}
+	p.pos++
+}
+
+// substitution records script between start and end as a nested one,
+// the word keeps its text
+func (p *parser) substitution(from, start, end, next int) {
+	p.inWord = true
+	p.current.subs = append(p.current.subs, string(p.input[start:end]))
+	p.word.WriteString(string(p.input[from:min(next, len(p.input))]))
+	p.pos = next
+}
+
+func (p *parser) endWord() {
+	if !p.inWord {
+		return
+	}
+	word := p.word.String()
+	p.word.Reset()
+	p.inWord = false
+
+	switch {
+	case p.pending == "<<" || p.pending == "<<-":
+		p.heredocs = append(p.heredocs, heredoc{delimiter: word, stripTabs: p.pending == "<<-"})
+		p.current.redirects = append(p.current.redirects, redirect{op: p.pending, target: word})
+	case p.pending != "":
+		p.current.redirects = append(p.current.redirects, redirect{op: p.pending, target: word})
+	default:
+		p.current.args = append(p.current.args, word)
+	}
+	p.pending = ""
+}
+
+func (p *parser) endCommand(piped bool) {
+	p.endWord()
+	p.pending = ""
+	command := p.current
+	p.current = simpleCommand{piped: piped}
+
+	// Assignments before the command and shell keywords are not commands
+	for len(command.args) > 0 && (isAssignment(command.args[0]) || keywords[command.args[0]]) {
+		command.args = command.args[1:]
+	}
+	if len(command.args) > 0 || len(command.redirects) > 0 || len(command.subs) > 0 {
+		p.commands = append(p.commands, command)
+	}
+}
+
+// skipHeredocs moves past bodies of here-documents, their content is
+// data, not commands
+func (p *parser) skipHeredocs() {
+	for _, doc := range p.heredocs {
+		for p.pos < len(p.input) {
+			end := p.pos
+			for end < len(p.input) && p.input[end] != '\n' {
+				end++
+			}
+			line := string(p.input[p.pos:end])
+			p.pos = min(end+1, len(p.input))
+			if doc.stripTabs {
+				line = strings.TrimLeft(line, "\t")
+			}
+			if line == doc.delimiter {
+				break
+			}
+		}
+	}
+	p.heredocs = nil
+}
+
+func (p *parser) next(s string) bool {
+	return strings.HasPrefix(string(p.input[p.pos:min(p.pos+len(s), len(p.input))]), s)
+}
+
+// find returns position of r after start, or end of input
+func (p *parser) find(r rune, start int) int {
+	for i := start; i < len(p.input); i++ {
+		if p.input[i] == r {
+			return i
+		}
+	}
+	return len(p.input)
+}
+
+// closing returns position of the parenthesis closing one opened right
+// before start, or end of input
+func (p *parser) closing(start int) int {
+	depth := 1
+	for i := start; i < len(p.input); i++ {
+		switch p.input[i] {
+		case '\\':
+			i++
+		case '\'':
+			i = p.find('\'', i+1)
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.input)
+}
+
+var keywords = map[string]bool{
+	"if": true, "then": true, "else": true, "elif": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true, "for": true,
+	"case": true, "esac": true, "in": true, "!": true, "{": true, "}": true,
+	"time": true,
+}
+
+func isAssignment(word string) bool {
+	i := strings.IndexByte(word, '=')
+	if i <= 0 {
+		return false
+	}
+	for j, r := range word[:i] {
+		if r != '_' && !unicode.IsLetter(r) && (j == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return true
+}
+
+func isNumber(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
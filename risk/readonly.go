@@ -0,0 +1,446 @@
+package risk
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// ReadOnly returns an error unless every part of command only reads
+// information. Unlike Classify it allows known commands only, anything
+// it does not understand may change the system
+func ReadOnly(command string) error {
+	return readOnly(command, 0)
+}
+
+func readOnly(script string, depth int) error {
+	if depth > maxDepth {
+		return fmt.Errorf("command is nested too deep")
+	}
+	for _, cmd := range parse(script) {
+		for _, sub := range cmd.subs {
+			if err := readOnly(sub, depth+1); err != nil {
+				return err
+			}
+		}
+		for _, r := range cmd.redirects {
+			switch r.op {
+			case "<", "<<", "<<-", "<<<", "<&":
+			case ">&":
+				if !isNumber(r.target) && r.target != "-" && !harmlessDevice(r.target) {
+					return fmt.Errorf("output is redirected to %s", r.target)
+				}
+			default:
+				if !harmlessDevice(r.target) {
+					return fmt.Errorf("output is redirected to %s", r.target)
+				}
+			}
+		}
+
+		if straceOutput(cmd.args) {
+			return fmt.Errorf("strace -o writes its output to a file")
+		}
+		args, sudo := (&classifier{}).unwrap(cmd.args)
+		if sudo {
+			return fmt.Errorf("%s runs as root", cmd.args[0])
+		}
+		if len(args) == 0 {
+			continue
+		}
+		name := filepath.Base(args[0])
+		if script, ok := inlineScript(args); ok {
+			if err := readOnly(script, depth+1); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := readOnlyCommand(name, args[1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// straceOutput reports whether strace in args writes its trace to a
+// file, unwrap drops the option along with strace
+func straceOutput(args []string) bool {
+	for i, arg := range args {
+		if filepath.Base(arg) != "strace" {
+			continue
+		}
+		for _, option := range args[i+1:] {
+			if !strings.HasPrefix(option, "-") {
+				break
+			}
+			if strings.HasPrefix(option, "-o") || strings.HasPrefix(option, "--output") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// readers only read files or print information whatever their options
+var readers = map[string]bool{
+	"ls": true, "cat": true, "head": true, "tail": true, "grep": true, "egrep": true, "fgrep": true,
+	"wc": true, "cut": true, "tr": true, "nl": true, "od": true, "hexdump": true,
+	"strings": true, "file": true, "stat": true, "du": true, "df": true, "free": true, "uptime": true,
+	"uname": true, "whoami": true, "id": true, "groups": true, "who": true, "w": true, "last": true,
+	"pwd": true, "echo": true, "printf": true, "true": true, "false": true, "test": true, "[": true,
+	"which": true, "type": true, "whereis": true, "locate": true, "realpath": true,
+	"readlink": true, "basename": true, "dirname": true, "printenv": true, "ps": true, "pgrep": true,
+	"lsof": true, "netstat": true, "ss": true, "dig": true, "nslookup": true, "host": true,
+	"lsblk": true, "blkid": true, "findmnt": true, "lscpu": true, "lsusb": true, "lspci": true,
+	"sha1sum": true, "sha256sum": true, "sha512sum": true, "md5sum": true, "cksum": true,
+	"diff": true, "cmp": true, "comm": true, "column": true, "jq": true, "cd": true, "pushd": true,
+	"popd": true, "getent": true, "nproc": true, "arch": true, "rev": true, "fold": true, "expand": true,
+	"paste": true, "join": true, "seq": true, "sleep": true, "zcat": true, "zgrep": true, "xzcat": true,
+	"bzcat": true, "uniq": true,
+}
+
+// readerSubcommands are subcommands that only read information, keyed by
+// the command
+var readerSubcommands = map[string][]string{
+	"git": {"status", "log", "diff", "show", "blame", "ls-files", "ls-tree", "ls-remote", "rev-parse",
+		"rev-list", "describe", "shortlog", "grep", "cat-file", "for-each-ref", "name-rev", "merge-base",
+		"count-objects", "help", "version", "whatchanged"},
+	"systemctl": {"status", "show", "cat", "list-units", "list-unit-files", "list-timers", "is-active", "is-enabled", "is-failed"},
+	"docker":    {"ps", "images", "inspect", "logs", "version", "info", "top", "stats", "port", "history"},
+	"podman":    {"ps", "images", "inspect", "logs", "version", "info", "top", "stats", "port", "history"},
+	"kubectl":   {"get", "describe", "logs", "version", "explain", "top", "api-resources", "api-versions", "cluster-info"},
+	"go":        {"version", "env", "list", "doc"},
+	"pip":       {"list", "show", "freeze"},
+	"pip3":      {"list", "show", "freeze"},
+	"npm":       {"ls", "list", "view", "outdated"},
+	"apt":       {"list", "show", "policy", "search"},
+}
+
+// sedPrint matches sed scripts printing or deleting lines of output
+var sedPrint = regexp.MustCompile(`^(\d+|\$|/[^/]*/)?(,(\d+|\$|/[^/]*/))?[pd]$`)
+
+// readOnlyCommand checks a single command without wrappers
+func readOnlyCommand(name string, args []string) error {
+	set, operands := flags(args)
+
+	switch {
+	case name == "uniq" && len(operands) > 1:
+		return fmt.Errorf("uniq writes to %s", operands[1])
+	case readers[name]:
+		return nil
+	}
+
+	switch name {
+	case "find":
+		for _, arg := range args {
+			switch arg {
+			case "-delete", "-exec", "-execdir", "-ok", "-okdir", "-fprint", "-fprint0", "-fprintf", "-fls":
+				return fmt.Errorf("find %s may change files", arg)
+			}
+		}
+		return nil
+	case "sort":
+		if set["o"] || set["--output"] {
+			return fmt.Errorf("sort writes its output to a file")
+		}
+		if hasOption(set, "--compress-program") {
+			return fmt.Errorf("sort --compress-program runs a command")
+		}
+		return nil
+	case "rg", "ag":
+		// rg --pre and ag --pager run a command, -z runs decompressors
+		if hasOption(set, "--pre") || hasOption(set, "--pager") {
+			return fmt.Errorf("%s runs a preprocessor or pager", name)
+		}
+		if set["z"] || hasOption(set, "--search-zip") {
+			return fmt.Errorf("%s --search-zip runs a command", name)
+		}
+		return nil
+	case "sed":
+		scripts, err := sedScripts(args)
+		if err != nil {
+			return err
+		}
+		for _, script := range scripts {
+			if !readOnlySed(script) {
+				return fmt.Errorf("sed script %s may write files", script)
+			}
+		}
+		return nil
+	case "tree":
+		if set["o"] {
+			return fmt.Errorf("tree writes its output to a file")
+		}
+		return nil
+	case "date":
+		if set["s"] || set["--set"] {
+			return fmt.Errorf("date sets the clock")
+		}
+		return nil
+	case "hostname", "ifconfig":
+		if len(operands) > 1 || name == "hostname" && len(operands) > 0 {
+			return fmt.Errorf("%s changes network settings", name)
+		}
+		return nil
+	case "mount":
+		if len(operands) > 0 {
+			return fmt.Errorf("mount mounts file systems")
+		}
+		return nil
+	case "dmesg":
+		if set["c"] || set["C"] || set["--clear"] || set["--read-clear"] {
+			return fmt.Errorf("dmesg clears the kernel log")
+		}
+		return nil
+	case "journalctl":
+		for option := range set {
+			if strings.HasPrefix(option, "--vacuum") || option == "--rotate" || option == "--flush" {
+				return fmt.Errorf("journalctl %s changes the journal", option)
+			}
+		}
+		return nil
+	case "ip":
+		for _, operand := range operands {
+			switch operand {
+			case "add", "del", "delete", "set", "flush", "change", "replace", "append":
+				return fmt.Errorf("ip %s changes network settings", operand)
+			}
+		}
+		return nil
+	case "git":
+		return readOnlyGit(args)
+	case "go":
+		if set["w"] || set["u"] {
+			return fmt.Errorf("go env changes settings")
+		}
+	}
+
+	subcommands, ok := readerSubcommands[name]
+	if !ok {
+		return fmt.Errorf("%s is not a read-only command", name)
+	}
+	if len(operands) == 0 {
+		// Without a subcommand these tools print help
+		return nil
+	}
+	if !contains(subcommands, operands[0]) {
+		return fmt.Errorf("%s %s is not a read-only command", name, operands[0])
+	}
+	return nil
+}
+
+func readOnlyGit(args []string) error {
+	// Global options like -C dir come before the subcommand
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		// Settings like core.fsmonitor or core.pager run commands
+		if strings.HasPrefix(args[0], "-c") || strings.HasPrefix(args[0], "--config-env") {
+			return fmt.Errorf("git %s may run commands", strings.SplitN(args[0], "=", 2)[0])
+		}
+		if args[0] == "-C" && len(args) > 1 {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil
+	}
+	set, operands := flags(args[1:])
+
+	switch args[0] {
+	case "diff", "log", "show", "whatchanged":
+		if set["--output"] {
+			return fmt.Errorf("git %s writes its output to a file", args[0])
+		}
+		if hasOption(set, "--ext-diff") {
+			return fmt.Errorf("git %s --ext-diff runs a command", args[0])
+		}
+		return nil
+	case "ls-remote":
+		if set["u"] || hasOption(set, "--upload-pack") {
+			return fmt.Errorf("git ls-remote --upload-pack runs a command")
+		}
+		return nil
+	case "grep":
+		if set["O"] || hasOption(set, "--open-files-in-pager") {
+			return fmt.Errorf("git grep --open-files-in-pager runs a command")
+		}
+		return nil
+	case "branch", "tag":
+		if gitListing(args[1:]) {
+			return nil
+		}
+		return fmt.Errorf("git %s changes refs", args[0])
+	case "remote":
+		if len(operands) == 0 || operands[0] == "show" || operands[0] == "get-url" {
+			return nil
+		}
+		return fmt.Errorf("git remote %s changes remotes", operands[0])
+	case "stash", "worktree", "reflog":
+		if len(operands) == 0 && args[0] != "stash" || len(operands) > 0 && (operands[0] == "list" || operands[0] == "show") {
+			return nil
+		}
+		return fmt.Errorf("git %s changes the repository", args[0])
+	case "config":
+		for _, option := range []string{"e", "--edit", "--unset", "--unset-all", "--add", "--replace-all", "--rename-section", "--remove-section"} {
+			if set[option] {
+				return fmt.Errorf("git config changes settings")
+			}
+		}
+		for _, option := range []string{"l", "--list", "--get", "--get-all", "--get-regexp", "--get-urlmatch", "--show-origin"} {
+			if set[option] {
+				return nil
+			}
+		}
+		if len(operands) == 1 || len(operands) > 0 && (operands[0] == "list" || operands[0] == "get") {
+			return nil
+		}
+		return fmt.Errorf("git config changes settings")
+	}
+	if !contains(readerSubcommands["git"], args[0]) {
+		return fmt.Errorf("git %s is not a read-only command", args[0])
+	}
+	return nil
+}
+
+// gitListFlags are short flags of git branch and tag that only list
+var gitListFlags = "alrvin0123456789"
+
+// gitListOptions are long options of git branch and tag that only list,
+// true when they take a value
+var gitListOptions = map[string]bool{
+	"--all": false, "--remotes": false, "--verbose": false, "--list": false, "--ignore-case": false,
+	"--show-current": false, "--no-color": false, "--no-column": false, "--no-abbrev": false, "--omit-empty": false,
+	"--contains": true, "--no-contains": true, "--merged": true, "--no-merged": true, "--points-at": true,
+	"--sort": true, "--format": true, "--color": false, "--column": false, "--abbrev": false,
+}
+
+// gitListing reports whether git branch or tag arguments only list refs,
+// names without --list would create them
+func gitListing(args []string) bool {
+	list := false
+	var operands []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			operands = append(operands, args[i+1:]...)
+			i = len(args)
+		case strings.HasPrefix(arg, "--"):
+			name := strings.SplitN(arg, "=", 2)[0]
+			valued, ok := gitListOptions[name]
+			if !ok {
+				return false
+			}
+			list = list || name == "--list"
+			if valued && !strings.Contains(arg, "=") {
+				i++
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			if strings.Trim(arg[1:], gitListFlags) != "" {
+				return false
+			}
+			list = list || strings.Contains(arg, "l")
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return list || len(operands) == 0
+}
+
+// hasOption reports whether the long option name is set, abbreviated or not
+func hasOption(set map[string]bool, name string) bool {
+	for o := range set {
+		if len(o) > 3 && strings.HasPrefix(o, "--") && strings.HasPrefix(name, o) {
+			return true
+		}
+	}
+	return false
+}
+
+// sedLongOptions are long options of sed that neither edit files nor
+// take a script
+var sedLongOptions = map[string]bool{
+	"--quiet": true, "--silent": true, "--regexp-extended": true, "--null-data": true, "--separate": true,
+	"--unbuffered": true, "--posix": true, "--debug": true, "--sandbox": true, "--zero-terminated": true,
+}
+
+// sedScripts returns scripts given to sed in any form, options that edit
+// files or that it does not know are an error
+func sedScripts(args []string) ([]string, error) {
+	var scripts, operands []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			operands = append(operands, args[i+1:]...)
+			i = len(args)
+		case arg == "--expression" && i+1 < len(args):
+			i++
+			scripts = append(scripts, args[i])
+		case strings.HasPrefix(arg, "--expression="):
+			scripts = append(scripts, strings.TrimPrefix(arg, "--expression="))
+		case strings.HasPrefix(arg, "--"):
+			if !sedLongOptions[arg] {
+				return nil, fmt.Errorf("sed %s may edit files", strings.SplitN(arg, "=", 2)[0])
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Grouped flags like -ne 'p', the script follows e
+		group:
+			for j := 1; j < len(arg); j++ {
+				switch arg[j] {
+				case 'n', 'E', 'r', 's', 'u', 'z':
+					continue
+				case 'e':
+					if script := arg[j+1:]; script != "" {
+						scripts = append(scripts, script)
+					} else if i+1 < len(args) {
+						i++
+						scripts = append(scripts, args[i])
+					} else {
+						return nil, fmt.Errorf("sed -e has no script")
+					}
+					break group
+				default:
+					return nil, fmt.Errorf("sed -%c may edit files", arg[j])
+				}
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	if len(scripts) == 0 {
+		if len(operands) == 0 {
+			return nil, fmt.Errorf("sed has no script")
+		}
+		scripts = operands[:1]
+	}
+	return scripts, nil
+}
+
+// readOnlySed reports whether sed script only prints, deletes or
+// substitutes lines of its output
+func readOnlySed(script string) bool {
+	if sedPrint.MatchString(script) {
+		return true
+	}
+	// s/pattern/replacement/flags where flags can't write or execute
+	if len(script) < 2 || script[0] != 's' {
+		return false
+	}
+	delimiter := script[1]
+	var parts []string
+	start := 2
+	for i := 2; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			i++
+		case delimiter:
+			parts = append(parts, script[start:i])
+			start = i + 1
+		}
+	}
+	if len(parts) != 2 {
+		return false
+	}
+	return strings.Trim(script[start:], "gip0123456789") == ""
+}
@@ -0,0 +1,419 @@
+// Package risk estimates how dangerous a shell command is before it runs.
+// Commands are split into simple commands, and each is checked against
+// rules for deletions, privilege changes, downloaded scripts, writes
+// outside the working directory, package managers and git history
+// rewrites
+package risk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Level is how much damage a command may do
+type Level int
+
+// Levels from harmless to destructive
+const (
+	Low Level = iota
+	Medium
+	High
+	Critical
+)
+
+var levelNames = []string{"low", "medium", "high", "critical"}
+
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel parses level name
+func ParseLevel(name string) (Level, error) {
+	for i, levelName := range levelNames {
+		if name == levelName {
+			return Level(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown risk level: %s", name)
+}
+
+// Assessment is risk level of a command with reasons that raised it
+type Assessment struct {
+	Level   Level
+	Reasons []string
+}
+
+// String formats assessment as "high: runs as root; deletes recursively"
+func (a Assessment) String() string {
+	if len(a.Reasons) == 0 {
+		return a.Level.String()
+	}
+	return a.Level.String() + ": " + strings.Join(a.Reasons, "; ")
+}
+
+func (a *Assessment) raise(level Level, reason string, args ...interface{}) {
+	if level > a.Level {
+		a.Level = level
+	}
+	reason = fmt.Sprintf(reason, args...)
+	for _, r := range a.Reasons {
+		if r == reason {
+			return
+		}
+	}
+	a.Reasons = append(a.Reasons, reason)
+}
+
+// Classify assesses command run by bash in workDir, empty workDir is the
+// current directory
+func Classify(command, workDir string) Assessment {
+	if workDir == "" {
+		workDir, _ = os.Getwd()
+	}
+	c := classifier{workDir: filepath.Clean(workDir)}
+	if home, err := os.UserHomeDir(); err == nil {
+		c.home = filepath.Clean(home)
+	}
+	c.script(command, 0)
+	return c.assessment
+}
+
+type classifier struct {
+	workDir    string
+	home       string
+	assessment Assessment
+}
+
+// maxDepth limits nesting of sh -c, eval and substitutions
+const maxDepth = 5
+
+func (c *classifier) script(script string, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	commands := parse(script)
+	for i, cmd := range commands {
+		for _, sub := range cmd.subs {
+			c.script(sub, depth+1)
+		}
+		for _, r := range cmd.redirects {
+			c.redirect(r)
+		}
+
+		args, sudo := c.unwrap(cmd.args)
+		if len(args) == 0 {
+			continue
+		}
+		if sudo {
+			c.raise(High, "runs as root")
+		}
+		name := filepath.Base(args[0])
+
+		if interpreters[name] {
+			if i > 0 && cmd.piped && downloads(commands[:i]) {
+				c.raise(Critical, "pipes a downloaded script into %s", name)
+			}
+			for _, sub := range cmd.subs {
+				if downloads(parse(sub)) {
+					c.raise(Critical, "runs a downloaded script with %s", name)
+				}
+			}
+			if script, ok := inlineScript(args); ok {
+				c.script(script, depth+1)
+			}
+			if runsInlineCode(name, args) {
+				c.raise(Medium, "runs inline %s code", name)
+			}
+		}
+		c.command(name, args, depth)
+	}
+}
+
+func (c *classifier) raise(level Level, reason string, args ...interface{}) {
+	c.assessment.raise(level, reason, args...)
+}
+
+// unwrap drops commands running another command like sudo, env, nohup or
+// xargs and reports whether it runs as root
+func (c *classifier) unwrap(args []string) ([]string, bool) {
+	sudo := false
+	for len(args) > 0 {
+		name := filepath.Base(args[0])
+		valued, ok := wrappers[name]
+		if !ok {
+			break
+		}
+		switch name {
+		case "sudo", "doas":
+			sudo = true
+		case "su":
+			// su -c "command" runs the command as root
+			for i, arg := range args {
+				if arg == "-c" && i+1 < len(args) {
+					return []string{"sh", "-c", args[i+1]}, true
+				}
+			}
+			return nil, true
+		}
+
+		args = args[1:]
+		for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+			// env -S splits its value into a command line and runs it
+			if script, ok := splitString(name, args); ok {
+				return []string{"sh", "-c", script}, sudo
+			}
+			if strings.Contains(valued, " "+args[0]+" ") && len(args) > 1 {
+				args = args[2:]
+			} else {
+				args = args[1:]
+			}
+		}
+		switch {
+		case name == "env":
+			for len(args) > 0 && isAssignment(args[0]) {
+				args = args[1:]
+			}
+		case name == "timeout" && len(args) > 0:
+			// Duration comes before the command
+			args = args[1:]
+		}
+	}
+	return args, sudo
+}
+
+// wrappers run the command given in their arguments, values list
+// options taking a value
+var wrappers = map[string]string{
+	"sudo":    " -u -g -C -h -p -U -r -t -D ",
+	"doas":    " -u -C ",
+	"su":      "",
+	"env":     " -u -C ",
+	"nohup":   "",
+	"nice":    " -n ",
+	"ionice":  " -c -n -p ",
+	"timeout": " -s -k --signal --kill-after ",
+	"xargs":   " -I -n -P -L -d -E -s -a ",
+	"exec":    " -a ",
+	"command": "",
+	"builtin": "",
+	"stdbuf":  " -i -o -e ",
+	"watch":   " -n -d ",
+	"strace":  " -o -e -p ",
+	"chroot":  "",
+}
+
+var shells = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true,
+}
+
+var interpreters = map[string]bool{
+	"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
+	"python": true, "python3": true, "perl": true, "ruby": true, "node": true, "php": true,
+}
+
+var downloaders = map[string]bool{
+	"curl": true, "wget": true, "fetch": true, "aria2c": true,
+}
+
+// downloads reports whether any of commands downloads from the network
+func downloads(commands []simpleCommand) bool {
+	for _, cmd := range commands {
+		if len(cmd.args) > 0 && downloaders[filepath.Base(cmd.args[0])] {
+			return true
+		}
+		for _, sub := range cmd.subs {
+			if downloads(parse(sub)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitString returns value of env -S or --split-string starting args
+func splitString(name string, args []string) (string, bool) {
+	if name != "env" {
+		return "", false
+	}
+	switch arg := args[0]; {
+	case (arg == "-S" || arg == "--split-string") && len(args) > 1:
+		return strings.Join(args[1:], " "), true
+	case strings.HasPrefix(arg, "--split-string="):
+		return strings.Join(append([]string{strings.TrimPrefix(arg, "--split-string=")}, args[1:]...), " "), true
+	case strings.HasPrefix(arg, "-S") && len(arg) > 2:
+		return strings.Join(append([]string{arg[2:]}, args[1:]...), " "), true
+	}
+	return "", false
+}
+
+// inlineCode lists options of interpreters taking code to run
+var inlineCode = map[string][]string{
+	"python": {"-c"}, "python3": {"-c"}, "perl": {"-e", "-E"}, "ruby": {"-e"},
+	"node": {"-e", "-p", "--eval", "--print"}, "php": {"-r"},
+}
+
+// runsInlineCode reports whether interpreter args pass code to run,
+// short options may be grouped like perl -pe
+func runsInlineCode(name string, args []string) bool {
+	for _, arg := range args[1:] {
+		for _, option := range inlineCode[name] {
+			long := strings.HasPrefix(option, "--")
+			switch {
+			case long && (arg == option || strings.HasPrefix(arg, option+"=")):
+				return true
+			case !long && strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, option[1:]):
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inlineScript returns script of sh -c "script" and other shells
+func inlineScript(args []string) (string, bool) {
+	if !shells[filepath.Base(args[0])] {
+		return "", false
+	}
+	for i, arg := range args[1:] {
+		if arg == "-c" && i+2 < len(args) {
+			return args[i+2], true
+		}
+	}
+	return "", false
+}
+
+func (c *classifier) redirect(r redirect) {
+	switch r.op {
+	case ">", ">>", ">|", "&>", "&>>", "<>":
+		c.write(r.target, "redirects output to")
+	}
+}
+
+// write checks a file written by command, target of redirections, cp,
+// mv, tee and alike
+func (c *classifier) write(target, action string) {
+	if target == "" || target == "-" || isNumber(target) {
+		return
+	}
+	path, ok := c.resolve(target)
+	if !ok {
+		return
+	}
+	switch {
+	case harmlessDevice(path):
+	case strings.HasPrefix(path, "/dev/"):
+		c.raise(Critical, "%s device %s", action, target)
+	case systemPath(path):
+		c.raise(High, "%s system file %s", action, target)
+	case !c.inside(path) && !tempPath(path):
+		c.raise(Medium, "%s %s outside working directory", action, target)
+	}
+}
+
+// remove checks a file deleted by command
+func (c *classifier) remove(target string, recursive bool) {
+	path, ok := c.resolve(target)
+	if !ok {
+		return
+	}
+	switch {
+	case path == "/" || path == c.home:
+		c.raise(Critical, "deletes %s", target)
+	case systemPath(path) || systemDirs[path]:
+		c.raise(Critical, "deletes system files %s", target)
+	case path == c.workDir && recursive:
+		c.raise(High, "deletes the whole working directory")
+	case !c.inside(path) && !tempPath(path):
+		c.raise(High, "deletes %s outside working directory", target)
+	}
+}
+
+// resolve returns absolute clean path of a command argument, paths
+// depending on unknown variables can't be resolved
+func (c *classifier) resolve(target string) (string, bool) {
+	switch {
+	case target == "~" || strings.HasPrefix(target, "~/"):
+		target = c.home + target[1:]
+	case target == "$HOME" || strings.HasPrefix(target, "$HOME/"):
+		target = c.home + target[5:]
+	case target == "${HOME}" || strings.HasPrefix(target, "${HOME}/"):
+		target = c.home + target[7:]
+	}
+	if strings.ContainsAny(target, "$`") {
+		return "", false
+	}
+	// Globs are judged by the directory they expand in
+	if i := strings.IndexAny(target, "*?["); i >= 0 {
+		target = filepath.Dir(target[:i] + "x")
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(c.workDir, target)
+	}
+	return filepath.Clean(target), true
+}
+
+func (c *classifier) inside(path string) bool {
+	return path == c.workDir || strings.HasPrefix(path, c.workDir+string(filepath.Separator))
+}
+
+// systemDirs are directories holding the operating system
+var systemDirs = map[string]bool{
+	"/bin": true, "/boot": true, "/etc": true, "/lib": true, "/lib32": true, "/lib64": true,
+	"/opt": true, "/root": true, "/sbin": true, "/srv": true, "/sys": true, "/proc": true,
+	"/usr": true, "/var": true, "/home": true, "/Users": true, "/System": true, "/Library": true,
+}
+
+// systemPath reports whether path is inside the operating system files,
+// home and service data directories are only their roots
+func systemPath(path string) bool {
+	for _, dir := range []string{"/bin", "/boot", "/etc", "/lib", "/lib32", "/lib64", "/sbin", "/usr", "/sys", "/proc", "/var/lib", "/System", "/Library"} {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func tempPath(path string) bool {
+	for _, dir := range []string{"/tmp", "/var/tmp", os.TempDir()} {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func harmlessDevice(path string) bool {
+	switch path {
+	case "/dev/null", "/dev/stdout", "/dev/stderr", "/dev/tty", "/dev/zero":
+		return true
+	}
+	return strings.HasPrefix(path, "/dev/fd/")
+}
+
+// flags returns short flags and long options of args, short flags are
+// split so -rf gives r and f
+func flags(args []string) (map[string]bool, []string) {
+	set := map[string]bool{}
+	var operands []string
+	for i, arg := range args {
+		switch {
+		case arg == "--":
+			return set, append(operands, args[i+1:]...)
+		case strings.HasPrefix(arg, "--"):
+			set[strings.SplitN(arg, "=", 2)[0]] = true
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			for _, r := range arg[1:] {
+				set[string(r)] = true
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+	return set, operands
+}
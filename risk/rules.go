@@ -0,0 +1,305 @@
+package risk
+
+import "strings"
+
+// command applies rules of a single command to its arguments
+func (c *classifier) command(name string, args []string, depth int) {
+	set, operands := flags(args[1:])
+	if strings.HasPrefix(name, "mkfs.") {
+		name = "mkfs"
+	}
+
+	switch name {
+	case "rm", "rmdir", "unlink":
+		recursive := set["r"] || set["R"] || set["--recursive"]
+		switch {
+		case set["--no-preserve-root"]:
+			c.raise(Critical, "deletes / without protection")
+		case recursive:
+			c.raise(Medium, "deletes recursively")
+		default:
+			c.raise(Medium, "deletes files")
+		}
+		for _, target := range operands {
+			c.remove(target, recursive)
+		}
+
+	case "shred", "wipefs", "mkfs", "fdisk", "sfdisk", "parted", "mkswap":
+		c.raise(Critical, "destroys data with %s", name)
+	case "dd":
+		for _, arg := range args[1:] {
+			if strings.HasPrefix(arg, "of=") {
+				c.write(strings.TrimPrefix(arg, "of="), "writes raw data to")
+				c.raise(Medium, "writes raw data")
+			}
+		}
+
+	case "chmod", "chown", "chgrp", "chattr", "setfacl":
+		c.permissions(name, set, operands)
+
+	case "cp", "mv", "install", "ln", "rsync":
+		if len(operands) > 1 {
+			c.write(operands[len(operands)-1], name+" writes to")
+		}
+		if name == "mv" {
+			for _, source := range operands[:max(len(operands)-1, 0)] {
+				c.remove(source, true)
+			}
+		}
+		if name == "rsync" && set["--delete"] {
+			c.raise(High, "deletes files missing in the source")
+		}
+	case "tee", "truncate", "touch", "mkdir":
+		for _, target := range operands {
+			c.write(target, name+" writes to")
+		}
+	case "sed", "perl":
+		if set["i"] {
+			for _, target := range operands[1:] {
+				c.write(target, "edits")
+			}
+		}
+
+	case "find":
+		for i, arg := range args {
+			switch {
+			case arg == "-delete":
+				c.raise(High, "deletes files found by find")
+			case (arg == "-exec" || arg == "-execdir" || arg == "-ok") && i+1 < len(args):
+				c.script(strings.Join(args[i+1:], " "), depth+1)
+			}
+		}
+
+	case "eval":
+		c.raise(Medium, "runs a dynamically built command")
+		c.script(strings.Join(args[1:], " "), depth+1)
+
+	case "curl", "wget":
+		for _, option := range []string{"d", "F", "T", "--data", "--data-binary", "--data-raw", "--form", "--upload-file", "--post-file", "--post-data"} {
+			if set[option] {
+				c.raise(Medium, "sends data over the network")
+			}
+		}
+		if set["o"] || set["O"] || set["--output"] {
+			for i, arg := range args {
+				if (arg == "-o" || arg == "--output") && i+1 < len(args) {
+					c.write(args[i+1], "downloads to")
+				}
+			}
+		}
+
+	case "git":
+		c.git(args[1:])
+
+	case "apt", "apt-get", "aptitude", "yum", "dnf", "zypper", "apk", "emerge", "snap", "flatpak", "port", "pacman", "rpm", "dpkg":
+		if systemPackageChange(name, args[1:], set) {
+			c.raise(High, "changes system packages with %s", name)
+		}
+	case "brew", "pip", "pip3", "pipx", "npm", "yarn", "pnpm", "gem", "cargo", "go", "composer", "conda":
+		if len(operands) > 0 && packageChange[operands[0]] {
+			if set["g"] || set["--global"] {
+				c.raise(High, "changes global packages with %s", name)
+			} else {
+				c.raise(Medium, "installs or removes packages with %s", name)
+			}
+		}
+
+	case "shutdown", "reboot", "halt", "poweroff":
+		c.raise(High, "stops or restarts the machine")
+	case "init", "telinit":
+		c.raise(High, "changes system run level")
+	case "systemctl", "service", "launchctl":
+		for _, operand := range operands {
+			switch operand {
+			case "poweroff", "reboot", "halt", "suspend", "hibernate":
+				c.raise(High, "stops or restarts the machine")
+			case "stop", "restart", "disable", "mask", "kill", "unload", "remove":
+				c.raise(Medium, "changes system services")
+			}
+		}
+	case "kill", "killall", "pkill":
+		c.raise(Medium, "kills processes")
+		for _, operand := range operands {
+			if operand == "1" || operand == "-1" {
+				c.raise(Critical, "kills init or every process")
+			}
+		}
+
+	case "useradd", "userdel", "usermod", "groupadd", "groupdel", "passwd", "chpasswd", "visudo":
+		c.raise(High, "changes user accounts")
+	case "crontab":
+		if set["r"] {
+			c.raise(High, "removes all scheduled jobs")
+		} else {
+			c.raise(Medium, "changes scheduled jobs")
+		}
+	case "iptables", "ip6tables", "nft", "ufw", "firewall-cmd", "pfctl":
+		c.raise(High, "changes firewall rules")
+	case "mount", "umount", "swapoff", "sysctl", "modprobe", "insmod", "rmmod":
+		c.raise(High, "changes system configuration with %s", name)
+
+	case "kubectl", "helm":
+		if len(operands) > 0 && (operands[0] == "delete" || operands[0] == "uninstall" || operands[0] == "drain") {
+			c.raise(High, "deletes cluster resources")
+		}
+	case "docker", "podman":
+		if len(operands) > 0 {
+			switch operands[0] {
+			case "rm", "rmi", "prune", "kill":
+				c.raise(Medium, "removes containers or images")
+			case "system", "volume", "network", "container", "image":
+				if len(operands) > 1 && (operands[1] == "prune" || operands[1] == "rm") {
+					c.raise(High, "removes %s data", operands[0])
+				}
+			}
+		}
+	case "terraform", "tofu", "pulumi":
+		if len(operands) > 0 && (operands[0] == "destroy" || operands[0] == "apply" || operands[0] == "up") {
+			c.raise(High, "changes infrastructure")
+		}
+	}
+}
+
+func (c *classifier) permissions(name string, set map[string]bool, operands []string) {
+	recursive := set["R"] || set["--recursive"]
+	targets := operands
+	if name != "chattr" && len(operands) > 0 {
+		mode := operands[0]
+		targets = operands[1:]
+		if name == "chmod" {
+			switch {
+			case strings.Contains(mode, "777") || strings.Contains(mode, "666") || strings.Contains(mode, "o+w") || strings.Contains(mode, "a+w"):
+				c.raise(High, "makes files writable by everyone")
+			case strings.Contains(mode, "+s") || len(mode) == 4 && mode[0] >= '2' && mode[0] <= '7':
+				c.raise(High, "sets setuid or setgid bit")
+			}
+		}
+	}
+	for _, target := range targets {
+		path, ok := c.resolve(target)
+		if !ok {
+			continue
+		}
+		switch {
+		case path == "/" || systemDirs[path] || path == c.home:
+			if recursive {
+				c.raise(Critical, "changes permissions of %s recursively", target)
+			} else {
+				c.raise(High, "changes permissions of %s", target)
+			}
+		case systemPath(path):
+			c.raise(High, "changes permissions of system file %s", target)
+		case !c.inside(path) && !tempPath(path):
+			c.raise(Medium, "changes permissions of %s outside working directory", target)
+		}
+	}
+}
+
+func (c *classifier) git(args []string) {
+	// Global options like -C dir come before the subcommand
+	for len(args) > 1 && strings.HasPrefix(args[0], "-") {
+		if args[0] == "-C" || args[0] == "-c" {
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return
+	}
+	set, operands := flags(args[1:])
+
+	switch args[0] {
+	case "push":
+		forced := set["f"] || set["--force"] || set["--force-with-lease"] || set["--mirror"]
+		for _, operand := range operands {
+			forced = forced || strings.HasPrefix(operand, "+")
+			if strings.HasPrefix(operand, ":") || set["d"] || set["--delete"] {
+				c.raise(High, "deletes remote branches")
+			}
+		}
+		if forced {
+			c.raise(High, "force pushes, rewriting remote history")
+		} else {
+			c.raise(Medium, "publishes commits")
+		}
+	case "reset":
+		if set["--hard"] || set["--merge"] {
+			c.raise(High, "discards uncommitted changes")
+		}
+	case "clean":
+		if set["f"] || set["--force"] {
+			c.raise(High, "deletes untracked files")
+		}
+	case "filter-branch", "filter-repo", "replace":
+		c.raise(High, "rewrites git history")
+	case "rebase":
+		if !set["--abort"] && !set["--continue"] && !set["--skip"] {
+			c.raise(Medium, "rewrites git history")
+		}
+	case "commit":
+		if set["--amend"] {
+			c.raise(Medium, "rewrites the last commit")
+		}
+	case "checkout", "restore", "switch":
+		if set["f"] || set["--force"] || set["--discard-changes"] || contains(operands, ".") {
+			c.raise(Medium, "discards uncommitted changes")
+		}
+	case "branch":
+		if set["D"] || set["d"] && set["f"] {
+			c.raise(Medium, "deletes branches")
+		}
+	case "stash":
+		if len(operands) > 0 && (operands[0] == "drop" || operands[0] == "clear") {
+			c.raise(Medium, "drops stashed changes")
+		}
+	case "reflog":
+		if len(operands) > 0 && operands[0] == "expire" {
+			c.raise(High, "expires reflog, making lost commits unrecoverable")
+		}
+	case "gc":
+		if set["--prune"] {
+			c.raise(Medium, "prunes unreachable commits")
+		}
+	case "update-ref":
+		if set["d"] {
+			c.raise(High, "deletes git references")
+		}
+	}
+}
+
+// packageChange are subcommands of language package managers changing
+// installed packages
+var packageChange = map[string]bool{
+	"install": true, "uninstall": true, "remove": true, "add": true, "upgrade": true,
+	"update": true, "i": true, "rm": true, "un": true, "reinstall": true, "get": true,
+}
+
+// systemPackageChange reports whether system package manager command
+// installs, removes or upgrades packages
+func systemPackageChange(name string, args []string, set map[string]bool) bool {
+	switch name {
+	case "pacman":
+		return set["S"] || set["R"] || set["U"]
+	case "rpm":
+		return set["i"] || set["e"] || set["U"] || set["--install"] || set["--erase"]
+	case "dpkg":
+		return set["i"] || set["r"] || set["P"] || set["--install"] || set["--remove"] || set["--purge"]
+	}
+	for _, arg := range args {
+		switch arg {
+		case "install", "remove", "purge", "upgrade", "dist-upgrade", "full-upgrade", "autoremove",
+			"erase", "reinstall", "downgrade", "add", "del", "uninstall", "refresh":
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
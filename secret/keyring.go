@@ -0,0 +1,51 @@
+package secret
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringService is the Secret Service attribute all g8t secrets share,
+// the reference name is stored as account attribute
+const keyringService = "g8t"
+
+// KeyringAvailable reports whether Secret Service keyring can be used,
+// access goes through secret-tool from libsecret
+func KeyringAvailable() bool {
+	_, err := exec.LookPath("secret-tool")
+	return err == nil
+}
+
+// Store saves value in the keyring and returns reference to it
+func Store(name, value string) (string, error) {
+	if !KeyringAvailable() {
+		return "", fmt.Errorf("secret-tool is not installed, keyring is unavailable")
+	}
+
+	cmd := exec.Command("secret-tool", "store", "--label", "g8t "+name, "service", keyringService, "account", name)
+	cmd.Stdin = strings.NewReader(value)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to store secret in keyring: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	mu.Lock()
+	resolved[PrefixKeyring+name] = value
+	mu.Unlock()
+	return PrefixKeyring + name, nil
+}
+
+func keyringLookup(name string) (string, error) {
+	if !KeyringAvailable() {
+		return "", fmt.Errorf("secret-tool is not installed, cannot read keyring secret %s", name)
+	}
+
+	output, err := exec.Command("secret-tool", "lookup", "service", keyringService, "account", name).Output()
+	if err != nil {
+		return "", fmt.Errorf("keyring secret %s not found: %w", name, err)
+	}
+	return nonEmpty(string(output), "keyring "+name)
+}
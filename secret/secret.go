@@ -0,0 +1,114 @@
+// Package secret resolves credential references like env:NAME, file:PATH,
+// cmd:COMMAND and keyring:NAME to their values
+package secret
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reference prefixes
+const (
+	PrefixEnv     = "env:"
+	PrefixFile    = "file:"
+	PrefixCmd     = "cmd:"
+	PrefixKeyring = "keyring:"
+)
+
+// cmdTimeout limits commands printing secrets, e.g. password managers
+// waiting for unlock
+const cmdTimeout = 30 * time.Second
+
+var (
+	mu sync.Mutex
+	// resolved keeps values of references so commands run only once
+	resolved = map[string]string{}
+)
+
+// IsReference reports whether value refers to a secret stored elsewhere
+func IsReference(value string) bool {
+	for _, prefix := range []string{PrefixEnv, PrefixFile, PrefixCmd, PrefixKeyring} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve returns value of a reference, plain values are returned as is
+func Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if v, ok := resolved[value]; ok {
+		return v, nil
+	}
+
+	v, err := resolve(value)
+	if err != nil {
+		return "", err
+	}
+	resolved[value] = v
+	return v, nil
+}
+
+func resolve(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, PrefixEnv):
+		name := strings.TrimPrefix(value, PrefixEnv)
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return v, nil
+
+	case strings.HasPrefix(value, PrefixFile):
+		path := strings.TrimPrefix(value, PrefixFile)
+		if strings.HasPrefix(path, "~/") {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", fmt.Errorf("failed to get home directory: %w", err)
+			}
+			path = home + path[1:]
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file: %w", err)
+		}
+		return nonEmpty(string(data), path)
+
+	case strings.HasPrefix(value, PrefixCmd):
+		command := strings.TrimPrefix(value, PrefixCmd)
+		ctx, cancel := context.WithTimeout(context.Background(), cmdTimeout)
+		defer cancel()
+
+		cmd := exec.CommandContext(ctx, "sh", "-c", command)
+		cmd.Stdin = os.Stdin
+		cmd.Stderr = os.Stderr
+		output, err := cmd.Output()
+		if err != nil {
+			return "", fmt.Errorf("secret command %q failed: %w", command, err)
+		}
+		return nonEmpty(string(output), command)
+
+	default:
+		return keyringLookup(strings.TrimPrefix(value, PrefixKeyring))
+	}
+}
+
+// nonEmpty trims trailing newline most tools print after a secret
+func nonEmpty(value, origin string) (string, error) {
+	value = strings.TrimRight(value, "\r\n")
+	if value == "" {
+		return "", fmt.Errorf("secret from %s is empty", origin)
+	}
+	return value, nil
+}
@@ -0,0 +1,44 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("G8T_TEST_SECRET", "from-env")
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "plain", want: "plain"},
+		{value: "env:G8T_TEST_SECRET", want: "from-env"},
+		{value: "env:G8T_TEST_MISSING", wantErr: true},
+		{value: "file:" + path, want: "from-file"},
+		{value: "file:" + filepath.Join(dir, "missing"), wantErr: true},
+		{value: "cmd:echo from-cmd", want: "from-cmd"},
+		{value: "cmd:exit 1", wantErr: true},
+		{value: "cmd:true", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := Resolve(tt.value)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Resolve(%q) expected error, got %q", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Resolve(%q) = %q, %v, want %q", tt.value, got, err, tt.want)
+		}
+	}
+}
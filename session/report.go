@@ -0,0 +1,209 @@
+package session
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown renders session as a Markdown report, outputs of steps
+// are collapsible <details> blocks
+func (s *Session) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# g8t run %s\n\n", s.ID)
+	b.WriteString("| | |\n|---|---|\n")
+	for _, row := range s.overview() {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], escapeCell(row[1]))
+	}
+
+	fmt.Fprintf(&b, "\n## Task\n\n%s\n", s.Task)
+
+	b.WriteString("\n## Outcome\n\n")
+	if s.Status == StatusCompleted {
+		fmt.Fprintf(&b, "✅ Completed. %s\n", s.Summary)
+	} else {
+		fmt.Fprintf(&b, "❌ Failed: %s\n", s.Error)
+	}
+	if len(s.FilesChanged) > 0 {
+		b.WriteString("\n**Files changed:**\n\n")
+		for _, file := range s.FilesChanged {
+			fmt.Fprintf(&b, "- `%s`\n", file)
+		}
+	}
+	if len(s.FollowUps) > 0 {
+		b.WriteString("\n**Follow-ups:**\n\n")
+		for _, followUp := range s.FollowUps {
+			fmt.Fprintf(&b, "- %s\n", followUp)
+		}
+	}
+	if s.Answer != "" {
+		fmt.Fprintf(&b, "\n## Answer\n\n%s\n", strings.TrimSpace(s.Answer))
+	}
+
+	b.WriteString("\n## Steps\n")
+	if len(s.Steps) == 0 {
+		b.WriteString("\nNo commands were executed.\n")
+	}
+	for _, step := range s.Steps {
+		fmt.Fprintf(&b, "\n### Step %d\n\n", step.Number)
+		if step.Thought != "" {
+			fmt.Fprintf(&b, "**Thought:** %s\n\n", step.Thought)
+		}
+		b.WriteString(codeBlock("sh", step.Command))
+
+		status := "✅ Succeeded"
+		if !step.Success {
+			status = "❌ Failed: " + step.Error
+		}
+		if step.Duration > 0 {
+			status += " in " + formatDuration(step.Duration)
+		}
+		fmt.Fprintf(&b, "\n%s\n", status)
+
+		if step.Output != "" {
+			fmt.Fprintf(&b, "\n<details><summary>Output (%s)</summary>\n\n", lineCount(step.Output))
+			b.WriteString(codeBlock("", step.Output))
+			b.WriteString("\n</details>\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteHTML renders session as a self-contained HTML page
+func (s *Session) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, struct {
+		*Session
+		Overview [][2]string
+	}{s, s.overview()})
+}
+
+// overview lists name and value pairs shown at the top of reports
+func (s *Session) overview() [][2]string {
+	rows := [][2]string{
+		{"Status", s.Status},
+		{"Provider", strings.TrimSpace(s.Provider + " " + s.Model)},
+		{"Started", s.Started.Format(time.RFC3339)},
+		{"Duration", formatDuration(s.Finished.Sub(s.Started))},
+		{"Steps", fmt.Sprint(len(s.Steps))},
+		{"Tokens", fmt.Sprint(s.Usage.TotalTokens)},
+	}
+	if s.WorkDir != "" {
+		rows = append(rows, [2]string{"Directory", s.WorkDir})
+	}
+	if s.DryRun {
+		rows = append(rows, [2]string{"Mode", "dry run"})
+	}
+	return rows
+}
+
+// codeBlock fences text with more backticks than it contains
+func codeBlock(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence + "\n"
+}
+
+func escapeCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+func lineCount(text string) string {
+	n := strings.Count(strings.TrimRight(text, "\n"), "\n") + 1
+	if n == 1 {
+		return "1 line"
+	}
+	return fmt.Sprintf("%d lines", n)
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
+
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": formatDuration,
+	"lines":    lineCount,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>g8t run {{.ID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1f2328; line-height: 1.5; }
+h1 { font-size: 1.5em; }
+table { border-collapse: collapse; }
+td { padding: 0.2em 1em 0.2em 0; vertical-align: top; }
+td:first-child { color: #59636e; }
+pre { background: #f6f8fa; padding: 0.8em; overflow-x: auto; border-radius: 6px; white-space: pre-wrap; }
+.step { border: 1px solid #d1d9e0; border-radius: 6px; padding: 0 1em; margin: 1em 0; }
+.step.failed { border-color: #cf222e; }
+.ok { color: #1a7f37; }
+.fail { color: #cf222e; }
+summary { cursor: pointer; color: #59636e; }
+</style>
+</head>
+<body>
+<h1>g8t run {{.ID}}</h1>
+<table>
+{{- range .Overview}}
+<tr><td>{{index . 0}}</td><td>{{index . 1}}</td></tr>
+{{- end}}
+</table>
+<h2>Task</h2>
+<p>{{.Task}}</p>
+<h2>Outcome</h2>
+{{- if eq .Status "completed"}}
+<p class="ok">✅ Completed. {{.Summary}}</p>
+{{- else}}
+<p class="fail">❌ Failed: {{.Error}}</p>
+{{- end}}
+{{- if .FilesChanged}}
+<p><strong>Files changed:</strong></p>
+<ul>
+{{- range .FilesChanged}}
+<li><code>{{.}}</code></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .FollowUps}}
+<p><strong>Follow-ups:</strong></p>
+<ul>
+{{- range .FollowUps}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+{{- if .Answer}}
+<h2>Answer</h2>
+<pre>{{.Answer}}</pre>
+{{- end}}
+<h2>Steps</h2>
+{{- range .Steps}}
+<div class="step{{if not .Success}} failed{{end}}">
+<h3>Step {{.Number}}</h3>
+{{- if .Thought}}
+<p><strong>Thought:</strong> {{.Thought}}</p>
+{{- end}}
+<pre><code>{{.Command}}</code></pre>
+<p>{{if .Success}}<span class="ok">✅ Succeeded</span>{{else}}<span class="fail">❌ Failed: {{.Error}}</span>{{end}}{{if .Duration}} in {{duration .Duration}}{{end}}</p>
+{{- if .Output}}
+<details{{if not .Success}} open{{end}}><summary>Output ({{lines .Output}})</summary>
+<pre>{{.Output}}</pre>
+</details>
+{{- end}}
+</div>
+{{- else}}
+<p>No commands were executed.</p>
+{{- end}}
+</body>
+</html>
+`))
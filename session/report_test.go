@@ -0,0 +1,91 @@
+package session
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d1nch8g/g8t/agent"
+)
+
+func testSession() *Session {
+	started := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	return &Session{
+		ID:       "20240501-100000-aaaa",
+		Task:     "print readme",
+		Provider: "openai",
+		Model:    "gpt-4o",
+		Started:  started,
+		Finished: started.Add(90 * time.Second),
+		Status:   StatusFailed,
+		Error:    "reached maximum number of commands (2)",
+		Steps: []agent.Step{
+			{Number: 1, Thought: "read it", Command: "cat README.md", Output: "```go\n<script>alert(1)</script>\n```\n", Success: true, Duration: 1500 * time.Millisecond},
+			{Number: 2, Thought: "again", Command: "cat missing", Output: "cat: missing: No such file or directory\n", Error: "exit status 1", Duration: 20 * time.Millisecond},
+		},
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var b strings.Builder
+	if err := testSession().WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	report := b.String()
+
+	for _, want := range []string{
+		"| Provider | openai gpt-4o |",
+		"| Duration | 1m30s |",
+		"❌ Failed: reached maximum number of commands (2)",
+		"```sh\ncat README.md\n```",
+		// Output containing a fence gets a longer one
+		"<details><summary>Output (3 lines)</summary>\n\n````\n```go\n",
+		"✅ Succeeded in 1.5s",
+		"❌ Failed: exit status 1 in 20ms",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q:\n%s", want, report)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b strings.Builder
+	if err := testSession().WriteHTML(&b); err != nil {
+		t.Fatal(err)
+	}
+	report := b.String()
+
+	if strings.Contains(report, "<script>") {
+		t.Error("output is not escaped")
+	}
+	for _, want := range []string{
+		"&lt;script&gt;alert(1)&lt;/script&gt;",
+		`<div class="step failed">`,
+		"<details open><summary>",
+		"<td>Duration</td><td>1m30s</td>",
+	} {
+		if !strings.Contains(report, want) {
+			t.Errorf("report does not contain %q", want)
+		}
+	}
+}
+
+func TestWriteMarkdownResult(t *testing.T) {
+	s := testSession()
+	s.Status, s.Error = StatusCompleted, ""
+	s.Summary, s.Answer = "readme has it", "The project is **g8t**.\n"
+	s.FilesChanged, s.FollowUps = []string{"README.md"}, []string{"add a license"}
+
+	var b strings.Builder
+	if err := s.WriteMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := "✅ Completed. readme has it\n\n" +
+		"**Files changed:**\n\n- `README.md`\n\n" +
+		"**Follow-ups:**\n\n- add a license\n\n" +
+		"## Answer\n\nThe project is **g8t**.\n\n## Steps"
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("report does not contain result:\n%s", b.String())
+	}
+}
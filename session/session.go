@@ -0,0 +1,137 @@
+package session
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/d1nch8g/g8t/agent"
+	"github.com/d1nch8g/g8t/gpt"
+)
+
+// Run statuses
+const (
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// Session is a saved record of a single agent run
+type Session struct {
+	ID       string       `json:"id"`
+	Task     string       `json:"task"`
+	Provider string       `json:"provider"`
+	Model    string       `json:"model,omitempty"`
+	WorkDir  string       `json:"work_dir,omitempty"`
+	DryRun   bool         `json:"dry_run,omitempty"`
+	Started  time.Time    `json:"started"`
+	Finished time.Time    `json:"finished"`
+	Status   string       `json:"status"`
+	Error    string       `json:"error,omitempty"`
+	Summary  string       `json:"summary,omitempty"`
+	Answer   string       `json:"answer,omitempty"`
+	Usage    gpt.Usage    `json:"usage"`
+	Steps    []agent.Step `json:"steps"`
+	// FilesChanged, FollowUps and Data come from the result the model
+	// reported on completion
+	FilesChanged []string        `json:"files_changed,omitempty"`
+	FollowUps    []string        `json:"follow_ups,omitempty"`
+	Data         json.RawMessage `json:"data,omitempty"`
+}
+
+// Dir returns default directory of saved sessions
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".g8t", "sessions"), nil
+}
+
+// NewID returns sortable session id based on start time
+func NewID(started time.Time) string {
+	suffix := make([]byte, 2)
+	rand.Read(suffix)
+	return started.Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
+}
+
+// Save writes session to dir as <id>.json
+func (s *Session) Save(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, s.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
+
+// Load reads session by id, "last" selects the most recent one and
+// a unique id prefix is accepted as well
+func Load(dir, id string) (*Session, error) {
+	sessions, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if id == "last" {
+		if len(sessions) == 0 {
+			return nil, fmt.Errorf("no sessions found")
+		}
+		return sessions[0], nil
+	}
+
+	var found *Session
+	for _, s := range sessions {
+		if s.ID == id {
+			return s, nil
+		}
+		if strings.HasPrefix(s.ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("session id %s is ambiguous", id)
+			}
+			found = s
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return found, nil
+}
+
+// List returns saved sessions, most recent first
+func List(dir string) ([]*Session, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var sessions []*Session
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		var s Session
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("failed to parse session %s: %w", filepath.Base(file), err)
+		}
+		sessions = append(sessions, &s)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Started.After(sessions[j].Started)
+	})
+	return sessions, nil
+}
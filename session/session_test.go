@@ -0,0 +1,44 @@
+package session
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	started := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	for i, id := range []string{"20240501-100000-aaaa", "20240501-110000-bbbb", "20240501-110000-bbcc"} {
+		s := &Session{ID: id, Task: "task", Started: started.Add(time.Duration(i) * time.Hour)}
+		if err := s.Save(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		id      string
+		want    string
+		wantErr bool
+	}{
+		{id: "last", want: "20240501-110000-bbcc"},
+		{id: "20240501-100000-aaaa", want: "20240501-100000-aaaa"},
+		{id: "20240501-10", want: "20240501-100000-aaaa"},
+		{id: "20240501-110000-bb", wantErr: true},
+		{id: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		s, err := Load(dir, tt.id)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Load(%q) expected error", tt.id)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Load(%q): %v", tt.id, err)
+		}
+		if s.ID != tt.want {
+			t.Errorf("Load(%q) = %s, want %s", tt.id, s.ID, tt.want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package tracing
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d1nch8g/g8t/logger"
+)
+
+// Span kinds of OTLP
+const (
+	kindInternal = 1
+	kindClient   = 3
+)
+
+// statusError is OTLP status code of failed spans
+const statusError = 2
+
+// Tracer builds spans of a run from logger events and exports them in
+// OTLP-JSON format when the run finishes. Spans cover the run, each
+// step, each provider call and each command
+type Tracer struct {
+	// File receives one ExportTraceServiceRequest per line, optional
+	File string
+	// Endpoint is base URL of an OTLP/HTTP collector, optional
+	Endpoint   string
+	HTTPClient *http.Client
+
+	mu          sync.Mutex
+	traceID     string
+	model       string
+	provider    string
+	run         *span
+	step        *span
+	command     *span
+	spans       []*span
+	failedCalls int
+}
+
+type span struct {
+	TraceID      string      `json:"traceId"`
+	SpanID       string      `json:"spanId"`
+	ParentSpanID string      `json:"parentSpanId,omitempty"`
+	Name         string      `json:"name"`
+	Kind         int         `json:"kind"`
+	Start        string      `json:"startTimeUnixNano"`
+	End          string      `json:"endTimeUnixNano"`
+	Attributes   []attribute `json:"attributes,omitempty"`
+	Status       *status     `json:"status,omitempty"`
+
+	start time.Time
+}
+
+type attribute struct {
+	Key   string         `json:"key"`
+	Value attributeValue `json:"value"`
+}
+
+type attributeValue struct {
+	String *string  `json:"stringValue,omitempty"`
+	Int    *string  `json:"intValue,omitempty"`
+	Bool   *bool    `json:"boolValue,omitempty"`
+	Double *float64 `json:"doubleValue,omitempty"`
+}
+
+type status struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// NewTracer creates tracer exporting to file, endpoint or both
+func NewTracer(file, endpoint string) *Tracer {
+	return &Tracer{
+		File:       file,
+		Endpoint:   endpoint,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Level makes tracer receive every event
+func (t *Tracer) Level() logger.Level {
+	return logger.LevelDebug
+}
+
+func (t *Tracer) Write(e logger.Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch e.Type {
+	case logger.EventRunStarted:
+		t.traceID = randomID(16)
+		t.model = e.Model
+		t.provider = e.Provider
+		t.run = t.startSpan("g8t.run", kindInternal, nil, e.Time)
+		t.run.set("g8t.task", e.Task)
+		t.run.set("gen_ai.system", e.Provider)
+		t.run.set("gen_ai.request.model", e.Model)
+		t.run.set("g8t.max_commands", e.MaxCommands)
+		t.run.set("g8t.dry_run", e.DryRun)
+
+	case logger.EventStepStarted:
+		if t.run == nil {
+			return
+		}
+		t.endStep(e.Time)
+		t.step = t.startSpan("g8t.step", kindInternal, t.run, e.Time)
+		t.step.set("g8t.step", e.Step)
+
+	case logger.EventModelResponse:
+		if t.step == nil {
+			return
+		}
+		call := t.startSpan(strings.TrimSpace("chat "+t.model), kindClient, t.step, e.Time.Add(-e.Duration))
+		call.set("gen_ai.operation.name", "chat")
+		call.set("gen_ai.system", t.provider)
+		call.set("gen_ai.request.model", t.model)
+		call.set("g8t.retries", t.failedCalls)
+		if e.Error != "" {
+			t.failedCalls++
+			call.fail(e.Error)
+		} else {
+			t.failedCalls = 0
+			call.set("gen_ai.usage.input_tokens", e.InputTokens)
+			call.set("gen_ai.usage.output_tokens", e.OutputTokens)
+			call.set("g8t.usage.reasoning_tokens", e.ReasoningTokens)
+			call.set("g8t.cached", e.Cached)
+		}
+		call.end(e.Time)
+
+	case logger.EventCommandStarted:
+		if t.step == nil {
+			return
+		}
+		t.command = t.startSpan("g8t.command", kindInternal, t.step, e.Time)
+		t.command.set("g8t.command", e.Command)
+		if e.Risk != "" {
+			t.command.set("g8t.command.risk", e.Risk)
+		}
+
+	case logger.EventCommandFinished:
+		if t.command == nil {
+			return
+		}
+		if e.ExitCode != nil {
+			t.command.set("process.exit.code", *e.ExitCode)
+		}
+		if e.Error != "" {
+			t.command.fail(e.Error)
+		}
+		t.command.end(e.Time)
+		t.command = nil
+
+	case logger.EventTaskCompleted, logger.EventRunFailed:
+		if t.run == nil {
+			return
+		}
+		t.endStep(e.Time)
+		t.run.set("g8t.steps", e.Steps)
+		t.run.set("gen_ai.usage.input_tokens", e.InputTokens)
+		t.run.set("gen_ai.usage.output_tokens", e.OutputTokens)
+		if e.Type == logger.EventRunFailed {
+			t.run.fail(e.Error)
+		}
+		t.run.end(e.Time)
+	}
+}
+
+// Flush ends spans left open and exports all spans of the run
+func (t *Tracer) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.spans) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for _, s := range t.spans {
+		if s.End == "" {
+			s.end(now)
+		}
+	}
+
+	data, err := json.Marshal(t.request())
+	t.spans = nil
+	t.run, t.step, t.command = nil, nil, nil
+	if err != nil {
+		return fmt.Errorf("failed to encode trace: %w", err)
+	}
+
+	if t.File != "" {
+		if err := t.writeFile(data); err != nil {
+			return err
+		}
+	}
+	if t.Endpoint != "" {
+		if err := t.post(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// request wraps spans into ExportTraceServiceRequest
+func (t *Tracer) request() interface{} {
+	type scopeSpans struct {
+		Scope map[string]string `json:"scope"`
+		Spans []*span           `json:"spans"`
+	}
+	type resourceSpans struct {
+		Resource   map[string][]attribute `json:"resource"`
+		ScopeSpans []scopeSpans           `json:"scopeSpans"`
+	}
+
+	resource := &span{}
+	resource.set("service.name", "g8t")
+	return map[string][]resourceSpans{
+		"resourceSpans": {{
+			Resource:   map[string][]attribute{"attributes": resource.Attributes},
+			ScopeSpans: []scopeSpans{{Scope: map[string]string{"name": "github.com/d1nch8g/g8t"}, Spans: t.spans}},
+		}},
+	}
+}
+
+func (t *Tracer) writeFile(data []byte) error {
+	path := t.File
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("failed to get home directory: %w", err)
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create trace directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write trace file: %w", err)
+	}
+	return f.Close()
+}
+
+func (t *Tracer) post(data []byte) error {
+	url := strings.TrimSuffix(t.Endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+
+	resp, err := t.HTTPClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to export trace: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to export trace: collector returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (t *Tracer) startSpan(name string, kind int, parent *span, start time.Time) *span {
+	s := &span{
+		TraceID: t.traceID,
+		SpanID:  randomID(8),
+		Name:    name,
+		Kind:    kind,
+		Start:   unixNano(start),
+		start:   start,
+	}
+	if parent != nil {
+		s.ParentSpanID = parent.SpanID
+	}
+	t.spans = append(t.spans, s)
+	return s
+}
+
+func (t *Tracer) endStep(end time.Time) {
+	if t.command != nil {
+		t.command.end(end)
+		t.command = nil
+	}
+	if t.step != nil {
+		t.step.end(end)
+		t.step = nil
+	}
+}
+
+func (s *span) end(end time.Time) {
+	if end.Before(s.start) {
+		end = s.start
+	}
+	s.End = unixNano(end)
+}
+
+func (s *span) fail(message string) {
+	s.Status = &status{Code: statusError, Message: message}
+}
+
+func (s *span) set(key string, value interface{}) {
+	var v attributeValue
+	switch value := value.(type) {
+	case string:
+		v.String = &value
+	case int:
+		n := fmt.Sprint(value)
+		v.Int = &n
+	case bool:
+		v.Bool = &value
+	case float64:
+		v.Double = &value
+	default:
+		text := fmt.Sprint(value)
+		v.String = &text
+	}
+	s.Attributes = append(s.Attributes, attribute{Key: key, Value: v})
+}
+
+func unixNano(t time.Time) string {
+	return fmt.Sprint(t.UnixNano())
+}
+
+func randomID(size int) string {
+	id := make([]byte, size)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/d1nch8g/g8t/logger"
+)
+
+type exportRequest struct {
+	ResourceSpans []struct {
+		ScopeSpans []struct {
+			Spans []struct {
+				TraceID      string `json:"traceId"`
+				SpanID       string `json:"spanId"`
+				ParentSpanID string `json:"parentSpanId"`
+				Name         string `json:"name"`
+				Start        string `json:"startTimeUnixNano"`
+				End          string `json:"endTimeUnixNano"`
+				Attributes   []struct {
+					Key   string                 `json:"key"`
+					Value map[string]interface{} `json:"value"`
+				} `json:"attributes"`
+				Status *struct {
+					Code int `json:"code"`
+				} `json:"status"`
+			} `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+func traceRun(tracer *Tracer) {
+	code := 1
+	log := logger.NewWithSinks(tracer)
+	log.RunStarted("openai", "gpt-4o", "list files", 5, false)
+	log.StepStarted(1)
+	log.ModelResponse(logger.ModelResponse{Duration: time.Millisecond, Error: "rate limited"})
+	log.StepStarted(2)
+	log.ModelResponse(logger.ModelResponse{Duration: time.Millisecond, InputTokens: 10, OutputTokens: 5})
+	log.CommandStarted("ls", "look", "low", nil)
+	log.CommandFinished("", code, time.Millisecond, logger.DecisionAuto, nil)
+	log.RunFinished(logger.RunSummary{Completed: true, Steps: 2})
+}
+
+func TestTracerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces", "g8t.jsonl")
+	tracer := NewTracer(path, "")
+	traceRun(tracer)
+	if err := tracer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request exportRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		t.Fatal(err)
+	}
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+
+	names := []string{"g8t.run", "g8t.step", "chat gpt-4o", "g8t.step", "chat gpt-4o", "g8t.command"}
+	if len(spans) != len(names) {
+		t.Fatalf("got %d spans, want %d", len(spans), len(names))
+	}
+	parents := []int{-1, 0, 1, 0, 3, 3}
+	for i, s := range spans {
+		if s.Name != names[i] {
+			t.Errorf("span %d is %s, want %s", i, s.Name, names[i])
+		}
+		if s.TraceID != spans[0].TraceID || len(s.TraceID) != 32 || len(s.SpanID) != 16 {
+			t.Errorf("span %d has invalid ids %s %s", i, s.TraceID, s.SpanID)
+		}
+		if parents[i] >= 0 && s.ParentSpanID != spans[parents[i]].SpanID {
+			t.Errorf("span %d has wrong parent", i)
+		}
+		if s.Start == "" || s.End == "" || s.End < s.Start {
+			t.Errorf("span %d has invalid times %s %s", i, s.Start, s.End)
+		}
+	}
+
+	if spans[2].Status == nil || spans[2].Status.Code != statusError {
+		t.Error("failed provider call is not marked as error")
+	}
+	attributes := map[string]interface{}{}
+	for _, a := range spans[4].Attributes {
+		for _, v := range a.Value {
+			attributes[a.Key] = v
+		}
+	}
+	if attributes["g8t.retries"] != "1" || attributes["gen_ai.usage.input_tokens"] != "10" {
+		t.Errorf("unexpected provider call attributes %v", attributes)
+	}
+}
+
+func TestTracerEndpoint(t *testing.T) {
+	var path, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	tracer := NewTracer("", server.URL)
+	traceRun(tracer)
+	if err := tracer.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v1/traces" || contentType != "application/json" {
+		t.Errorf("unexpected request %s %s", path, contentType)
+	}
+	var request exportRequest
+	if err := json.Unmarshal(body, &request); err != nil || len(request.ResourceSpans) != 1 {
+		t.Errorf("invalid export request: %v", err)
+	}
+}
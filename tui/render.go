@@ -0,0 +1,355 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/d1nch8g/g8t/gpt"
+)
+
+// ANSI styles, applied to whole lines after they are cut to width
+const (
+	styleReset   = "\x1b[0m"
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleGreen   = "\x1b[32m"
+	styleYellow  = "\x1b[33m"
+	styleCyan    = "\x1b[36m"
+)
+
+// thoughtLines is height of the thought pane
+const thoughtLines = 3
+
+// render lays out the screen as exactly height lines
+func (s *state) render(width, height int, now time.Time) []string {
+	var top, bottom []string
+
+	top = append(top, style(styleReverse, spread(" g8t · "+s.status()+" · "+s.target(), s.counters(now)+" ", width)))
+	top = append(top, style(styleBold, fit("Task: "+s.task, width)))
+
+	top = append(top, section("Thought", width))
+	thought := wrap(s.thought, width)
+	for i := 0; i < thoughtLines; i++ {
+		line := ""
+		if i < len(thought) {
+			line = thought[i]
+		}
+		top = append(top, style(styleDim, line))
+	}
+
+	var output []string
+	final := s.final()
+	if len(final) > 0 {
+		// Answer and result take place of the last command, long lines
+		// are wrapped and short ones keep their indentation
+		title := "Result"
+		if s.outcome.Answer != "" {
+			title = "Answer"
+		}
+		top = append(top, section(title, width))
+		for _, line := range final {
+			if utf8.RuneCountInString(line) <= width {
+				output = append(output, line)
+			} else {
+				output = append(output, wrap(line, width)...)
+			}
+		}
+	} else if current := s.last(); current != nil {
+		top = append(top, section("Command", width))
+		top = append(top, style(styleBold, fit("$ "+current.command, width)))
+		if line := current.riskLine(); line != "" {
+			color := styleRed
+			if current.risk == "medium" {
+				color = styleYellow
+			}
+			top = append(top, style(color, fit(line, width)))
+		}
+		output = tail(lines(current.output), 0)
+	} else {
+		top = append(top, section("Command", width), "")
+	}
+
+	if s.message != "" {
+		bottom = append(bottom, style(styleYellow, fit(s.message, width)))
+	}
+	bottom = append(bottom, s.footer(width))
+
+	// Remaining height is shared by live output and the history
+	free := height - len(top) - len(bottom) - 1
+	outputHeight := free * 2 / 5
+	if len(final) > 0 && len(output) > outputHeight {
+		// The result is what is left to read, history keeps a few rows
+		outputHeight = max(outputHeight, min(len(output), free-3))
+	}
+	if outputHeight < 1 {
+		outputHeight = 1
+	}
+	historyHeight := free - outputHeight
+	if historyHeight < 1 {
+		historyHeight = 1
+	}
+
+	shown := tail(output, outputHeight)
+	if len(final) > 0 && len(output) > outputHeight {
+		// Answers are read from the start
+		shown = output[:outputHeight]
+	}
+
+	screen := top
+	for _, line := range pad(shown, outputHeight) {
+		screen = append(screen, fit(line, width))
+	}
+	screen = append(screen, section(fmt.Sprintf("History (%d)", len(s.history)), width))
+	screen = append(screen, pad(s.historyRows(width, historyHeight), historyHeight)...)
+	screen = append(screen, bottom...)
+
+	if len(screen) > height {
+		screen = append(screen[:height-1], screen[len(screen)-1])
+	}
+	return screen
+}
+
+// final returns answer, changed files and follow-ups of a completed run
+func (s *state) final() []string {
+	e := s.outcome
+	text := lines(e.Answer)
+	if len(e.FilesChanged) > 0 {
+		if len(text) > 0 {
+			text = append(text, "")
+		}
+		text = append(text, "Files changed: "+strings.Join(e.FilesChanged, ", "))
+	}
+	for _, followUp := range e.FollowUps {
+		text = append(text, "Next: "+followUp)
+	}
+	return text
+}
+
+func (s *state) status() string {
+	switch {
+	case s.finished && s.completed:
+		return "COMPLETED"
+	case s.finished && s.aborted:
+		return "ABORTED"
+	case s.finished:
+		return "FAILED"
+	case s.aborted:
+		return "ABORTING"
+	case s.waiting:
+		return "WAITING FOR APPROVAL"
+	case s.paused:
+		return "PAUSED"
+	}
+	return "RUNNING"
+}
+
+func (s *state) target() string {
+	if s.model == "" {
+		return s.provider
+	}
+	return s.provider + "/" + s.model
+}
+
+// counters formats step, tokens, cost and time
+func (s *state) counters(now time.Time) string {
+	elapsed := time.Duration(0)
+	if !s.started.IsZero() {
+		if s.finished {
+			now = s.finishedAt
+		}
+		elapsed = now.Sub(s.started).Truncate(time.Second)
+	}
+
+	cost := "cost n/a"
+	if price, ok := gpt.LookupPrice(s.model); ok {
+		cost = fmt.Sprintf("~$%.4f", price.Cost(s.usage))
+	}
+
+	return fmt.Sprintf("step %d/%d · %s in / %s out · %s · %s",
+		s.step, s.maxCommands, tokens(s.usage.InputTokens), tokens(s.usage.OutputTokens), cost, elapsed)
+}
+
+func (s *state) footer(width int) string {
+	switch {
+	case s.finished:
+		result := "Run finished"
+		if s.result != "" {
+			result += ": " + s.result
+		}
+		return style(styleReverse, fit(" "+result+" · ↑/↓ select · enter expand · q quit", width))
+	case s.waiting && s.approval:
+		risk := ""
+		if current := s.last(); current != nil {
+			risk = strings.ToUpper(current.risk) + " risk, "
+		}
+		return style(styleReverse+styleRed, fit(" "+risk+"approval required. a approve · s skip · q abort", width))
+	case s.waiting:
+		return style(styleReverse+styleYellow, fit(" Run this command? a approve · s skip · p resume · q abort", width))
+	}
+	return style(styleReverse, fit(" p pause · a approve · s skip · q abort · ↑/↓ select · enter expand", width))
+}
+
+// historyRows lists commands, the selected one is highlighted and
+// shows its output below when expanded
+func (s *state) historyRows(width, height int) []string {
+	var rows []string
+	selectedRow := 0
+	for i, e := range s.history {
+		mark, color := "…", styleCyan
+		switch {
+		case e.done && e.err == "":
+			mark, color = "✔", styleGreen
+		case e.done:
+			mark, color = "✘", styleRed
+		}
+		row := fit(fmt.Sprintf("%s %3d  %s", mark, e.step, e.command), width-8)
+		if e.done {
+			row = fmt.Sprintf("%-*s %7s", width-8, row, e.duration.Round(time.Millisecond*100))
+		}
+		row = fit(row, width)
+
+		if i != s.selected {
+			rows = append(rows, style(color, row))
+			continue
+		}
+		selectedRow = len(rows)
+		rows = append(rows, style(styleReverse+color, row))
+		if !s.expanded {
+			continue
+		}
+		if e.err != "" {
+			rows = append(rows, style(styleRed, fit("    error: "+e.err, width)))
+		}
+		if line := e.riskLine(); line != "" {
+			rows = append(rows, style(styleYellow, fit("    "+line, width)))
+		}
+		if e.thought != "" {
+			rows = append(rows, style(styleDim, fit("    thought: "+e.thought, width)))
+		}
+		for _, line := range lines(e.output) {
+			rows = append(rows, fit("    "+line, width))
+		}
+	}
+
+	// Keep the selected row visible, with its output when expanded
+	start := 0
+	if selectedRow >= height {
+		start = selectedRow - height/3
+	}
+	if start+height > len(rows) {
+		start = len(rows) - height
+	}
+	if start < 0 {
+		start = 0
+	}
+	end := start + height
+	if end > len(rows) {
+		end = len(rows)
+	}
+	return rows[start:end]
+}
+
+// riskLine describes risk of commands above low risk
+func (e *entry) riskLine() string {
+	if e.risk == "" || e.risk == "low" {
+		return ""
+	}
+	return "⚠ " + strings.ToUpper(e.risk) + " risk: " + strings.Join(e.reasons, "; ")
+}
+
+func section(title string, width int) string {
+	line := "── " + title + " "
+	if n := width - utf8.RuneCountInString(line); n > 0 {
+		line += strings.Repeat("─", n)
+	}
+	return style(styleCyan, fit(line, width))
+}
+
+// spread places left and right parts at the edges of a line
+func spread(left, right string, width int) string {
+	gap := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	if gap < 1 {
+		return fit(left+" "+right, width)
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+// fit cuts line to width and drops control characters that would
+// break the layout
+func fit(line string, width int) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range strings.ReplaceAll(line, "\t", "    ") {
+		if r < 0x20 || r == 0x7f {
+			continue
+		}
+		if n == width {
+			break
+		}
+		b.WriteRune(r)
+		n++
+	}
+	return b.String()
+}
+
+func style(codes, line string) string {
+	if line == "" {
+		return ""
+	}
+	return codes + line + styleReset
+}
+
+// wrap splits text into lines of at most width runes at spaces
+func wrap(text string, width int) []string {
+	var result []string
+	for _, paragraph := range lines(text) {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				result = append(result, fit(line, width))
+				line = word
+			}
+		}
+		result = append(result, fit(line, width))
+	}
+	return result
+}
+
+func lines(text string) []string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// tail returns last n lines, all of them when n is zero
+func tail(lines []string, n int) []string {
+	if n > 0 && len(lines) > n {
+		return lines[len(lines)-n:]
+	}
+	return lines
+}
+
+func pad(lines []string, n int) []string {
+	for len(lines) < n {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+func tokens(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprint(n)
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/d1nch8g/g8t/logger"
+)
+
+func TestRender(t *testing.T) {
+	code := 0
+	s := &state{follow: true, started: time.Unix(0, 0)}
+	for _, e := range []logger.Event{
+		{Type: logger.EventRunStarted, Task: "list files", Provider: "openai", Model: "gpt-4o", MaxCommands: 10},
+		{Type: logger.EventStepStarted, Step: 1},
+		{Type: logger.EventModelResponse, Thought: "look around", InputTokens: 2000, OutputTokens: 100},
+		{Type: logger.EventCommandStarted, Step: 1, Command: "ls"},
+		{Type: logger.EventCommandOutput, Data: "a.go\nb.go\n"},
+		{Type: logger.EventCommandFinished, ExitCode: &code, Duration: time.Second},
+		{Type: logger.EventStepStarted, Step: 2},
+		{Type: logger.EventCommandStarted, Step: 2, Command: "cat a.go\tb.go", Risk: "medium", RiskReasons: []string{"reads files"}},
+	} {
+		s.apply(e)
+	}
+	s.paused = true
+	s.selected, s.follow, s.expanded = 0, false, true
+
+	screen := s.render(90, 20, time.Unix(65, 0))
+	if len(screen) != 20 {
+		t.Fatalf("screen has %d lines, want 20", len(screen))
+	}
+
+	ansi := regexp.MustCompile("\x1b\\[[0-9;]*m")
+	var plain []string
+	for _, line := range screen {
+		line = ansi.ReplaceAllString(line, "")
+		if n := len([]rune(line)); n > 90 {
+			t.Errorf("line is %d runes wide: %q", n, line)
+		}
+		plain = append(plain, line)
+	}
+	text := strings.Join(plain, "\n")
+
+	for _, want := range []string{
+		"PAUSED · openai/gpt-4o",
+		"step 2/10 · 2.0k in / 100 out · ~$0.0060 · 1m5s",
+		"Task: list files",
+		"look around",
+		"$ cat a.go    b.go",
+		"⚠ MEDIUM risk: reads files",
+		"History (2)",
+		"✔   1  ls",
+		"    a.go\n    b.go",
+		"…   2  cat a.go",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("screen does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	got := strings.Join(parseKeys("p\x1b[A\x1b[Bq\r"), ",")
+	if got != "p,up,down,q,enter" {
+		t.Errorf("unexpected keys: %s", got)
+	}
+}
+
+func TestRenderAnswer(t *testing.T) {
+	s := &state{follow: true, started: time.Unix(0, 0)}
+	s.apply(logger.Event{Type: logger.EventRunStarted, Task: "which port?", Provider: "mock", MaxCommands: 5})
+	s.apply(logger.Event{Type: logger.EventTaskCompleted, Summary: "config has it", Answer: "Port **8080**\n\n    listen: 8080",
+		FilesChanged: []string{"notes.md"}, FollowUps: []string{"open the port"}})
+	s.finished = true
+
+	text := strings.Join(s.render(60, 20, time.Unix(5, 0)), "\n")
+	for _, want := range []string{"COMPLETED", "Answer", "Port **8080**", "    listen: 8080", "Files changed: notes.md", "Next: open the port"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("screen does not contain %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminal is the terminal taken over by the UI
+type terminal struct {
+	in    *os.File
+	out   *os.File
+	saved *unix.Termios
+}
+
+// openTerminal switches terminal to raw mode and alternate screen,
+// output processing is kept so newlines still return the carriage
+func openTerminal(in, out *os.File) (*terminal, error) {
+	saved, err := unix.IoctlGetTermios(int(in.Fd()), ioctlGetTermios)
+	if err != nil {
+		return nil, fmt.Errorf("terminal UI requires a terminal: %w", err)
+	}
+
+	raw := *saved
+	raw.Iflag &^= unix.IGNBRK | unix.BRKINT | unix.PARMRK | unix.ISTRIP | unix.INLCR | unix.IGNCR | unix.ICRNL | unix.IXON
+	raw.Lflag &^= unix.ECHO | unix.ECHONL | unix.ICANON | unix.ISIG | unix.IEXTEN
+	raw.Cflag &^= unix.CSIZE | unix.PARENB
+	raw.Cflag |= unix.CS8
+	raw.Cc[unix.VMIN] = 1
+	raw.Cc[unix.VTIME] = 0
+	if err := unix.IoctlSetTermios(int(in.Fd()), ioctlSetTermios, &raw); err != nil {
+		return nil, fmt.Errorf("failed to switch terminal to raw mode: %w", err)
+	}
+
+	// Alternate screen keeps the shell scrollback intact, cursor is hidden
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	return &terminal{in: in, out: out, saved: saved}, nil
+}
+
+// size returns width and height of the terminal
+func (t *terminal) size() (int, int) {
+	ws, err := unix.IoctlGetWinsize(int(t.out.Fd()), unix.TIOCGWINSZ)
+	if err != nil || ws.Col == 0 || ws.Row == 0 {
+		return 80, 24
+	}
+	return int(ws.Col), int(ws.Row)
+}
+
+// restore leaves alternate screen and restores terminal settings
+func (t *terminal) restore() {
+	fmt.Fprint(t.out, "\x1b[?25h\x1b[?1049l")
+	unix.IoctlSetTermios(int(t.in.Fd()), ioctlSetTermios, t.saved)
+}
+
+// resizeSignals notifies about changes of the terminal size
+func resizeSignals() chan os.Signal {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGWINCH)
+	return signals
+}
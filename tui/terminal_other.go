@@ -0,0 +1,26 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package tui
+
+import (
+	"fmt"
+	"os"
+)
+
+type terminal struct {
+	out *os.File
+}
+
+func openTerminal(in, out *os.File) (*terminal, error) {
+	return nil, fmt.Errorf("terminal UI is not supported on this platform")
+}
+
+func (t *terminal) size() (int, int) {
+	return 80, 24
+}
+
+func (t *terminal) restore() {}
+
+func resizeSignals() chan os.Signal {
+	return make(chan os.Signal)
+}
@@ -0,0 +1,349 @@
+package tui
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/d1nch8g/g8t/agent"
+	"github.com/d1nch8g/g8t/gpt"
+	"github.com/d1nch8g/g8t/logger"
+)
+
+// UI is a full screen view of a run. It receives events as a logger
+// sink and controls the agent through its gate
+type UI struct {
+	mu        sync.Mutex
+	state     state
+	changed   chan struct{}
+	decisions chan agent.Decision
+	cancel    context.CancelFunc
+}
+
+// entry is a command in the step history
+type entry struct {
+	step     int
+	command  string
+	thought  string
+	risk     string
+	reasons  []string
+	output   string
+	err      string
+	exitCode int
+	duration time.Duration
+	done     bool
+}
+
+// state is everything shown on the screen
+type state struct {
+	task        string
+	provider    string
+	model       string
+	maxCommands int
+	step        int
+	thought     string
+	history     []entry
+	usage       gpt.Usage
+	message     string
+	result      string
+	// outcome is the event that finished the run
+	outcome logger.Event
+
+	started    time.Time
+	finishedAt time.Time
+	paused     bool
+	waiting    bool
+	// approval is set while the waiting command needs approval by policy
+	approval  bool
+	aborted   bool
+	finished  bool
+	completed bool
+
+	// selected is index of history entry, follow keeps it on the newest
+	selected int
+	follow   bool
+	expanded bool
+}
+
+// New creates UI, add it as a sink and set its Gate on the agent
+func New() *UI {
+	return &UI{
+		state:     state{follow: true},
+		changed:   make(chan struct{}, 1),
+		decisions: make(chan agent.Decision, 1),
+	}
+}
+
+func (u *UI) Level() logger.Level {
+	return logger.LevelInfo
+}
+
+func (u *UI) Write(e logger.Event) {
+	u.mu.Lock()
+	u.state.apply(e)
+	u.mu.Unlock()
+	u.notify()
+}
+
+// Gate holds commands while the run is paused, or when policy requires
+// approval, until the user approves or skips them
+func (u *UI) Gate(p agent.Proposal) agent.Decision {
+	u.mu.Lock()
+	if u.state.aborted {
+		u.mu.Unlock()
+		return agent.DecisionAbort
+	}
+	if !u.state.paused && !p.Approval {
+		u.mu.Unlock()
+		return agent.DecisionRun
+	}
+	u.state.waiting = true
+	u.state.approval = p.Approval
+	u.mu.Unlock()
+	u.notify()
+
+	return <-u.decisions
+}
+
+// Run takes over the terminal and runs fn in background. The screen
+// stays after the run finishes until the user leaves with q
+func (u *UI) Run(fn func(ctx context.Context) error) error {
+	term, err := openTerminal(os.Stdin, os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer term.restore()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	u.mu.Lock()
+	u.cancel = cancel
+	u.state.started = time.Now()
+	u.mu.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+
+	keys := readKeys(os.Stdin)
+	resize := resizeSignals()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var runErr error
+	for {
+		u.draw(term)
+		select {
+		case <-u.changed:
+		case <-ticker.C:
+		case <-resize:
+		case runErr = <-done:
+			u.mu.Lock()
+			u.state.finished = true
+			u.state.finishedAt = time.Now()
+			u.mu.Unlock()
+		case key, ok := <-keys:
+			if !ok {
+				// Input is gone, nobody can leave the screen later or
+				// answer a waiting gate
+				cancel()
+				u.mu.Lock()
+				u.state.aborted = true
+				if u.state.waiting {
+					u.decide(agent.DecisionAbort)
+				}
+				u.mu.Unlock()
+				if !u.finished() {
+					runErr = <-done
+				}
+				return runErr
+			}
+			if u.key(key) {
+				return runErr
+			}
+		}
+	}
+}
+
+// Outcome returns the event that finished the run. The screen is gone
+// once Run returns, so callers print it again
+func (u *UI) Outcome() (logger.Event, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.state.outcome, u.state.outcome.Type != ""
+}
+
+func (u *UI) finished() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.state.finished
+}
+
+func (u *UI) notify() {
+	select {
+	case u.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (u *UI) draw(term *terminal) {
+	width, height := term.size()
+	u.mu.Lock()
+	lines := u.state.render(width, height, time.Now())
+	u.mu.Unlock()
+	io.WriteString(term.out, "\x1b[H"+strings.Join(lines, "\x1b[K\r\n")+"\x1b[K\x1b[J")
+}
+
+// key handles a key press and reports whether the UI should close
+func (u *UI) key(key string) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	s := &u.state
+
+	switch key {
+	case "p":
+		if s.waiting {
+			s.paused = false
+			u.decide(agent.DecisionApprove)
+		} else if !s.finished {
+			s.paused = !s.paused
+		}
+	case "a":
+		if s.waiting {
+			u.decide(agent.DecisionApprove)
+		}
+	case "s":
+		if s.waiting {
+			u.decide(agent.DecisionSkip)
+		}
+	case "q", "\x03":
+		if s.finished {
+			return true
+		}
+		if !s.aborted {
+			s.aborted = true
+			u.cancel()
+			if s.waiting {
+				u.decide(agent.DecisionAbort)
+			}
+		}
+	case "up", "k":
+		if s.selected > 0 {
+			s.selected--
+			s.follow = false
+		}
+	case "down", "j":
+		if s.selected < len(s.history)-1 {
+			s.selected++
+		}
+		s.follow = s.selected == len(s.history)-1
+	case "enter", " ":
+		s.expanded = !s.expanded
+	}
+	return false
+}
+
+// decide passes decision to the waiting gate, called with lock held
+func (u *UI) decide(decision agent.Decision) {
+	u.state.waiting = false
+	u.state.approval = false
+	u.decisions <- decision
+}
+
+// readKeys reads key presses, arrows and enter get names
+func readKeys(in io.Reader) chan string {
+	keys := make(chan string)
+	go func() {
+		defer close(keys)
+		buf := make([]byte, 16)
+		for {
+			n, err := in.Read(buf)
+			if err != nil {
+				return
+			}
+			for _, key := range parseKeys(string(buf[:n])) {
+				keys <- key
+			}
+		}
+	}()
+	return keys
+}
+
+func parseKeys(input string) []string {
+	var keys []string
+	for input != "" {
+		switch {
+		case strings.HasPrefix(input, "\x1b[A"):
+			keys, input = append(keys, "up"), input[3:]
+		case strings.HasPrefix(input, "\x1b[B"):
+			keys, input = append(keys, "down"), input[3:]
+		case input[0] == '\r' || input[0] == '\n':
+			keys, input = append(keys, "enter"), input[1:]
+		default:
+			keys, input = append(keys, input[:1]), input[1:]
+		}
+	}
+	return keys
+}
+
+func (s *state) apply(e logger.Event) {
+	switch e.Type {
+	case logger.EventRunStarted:
+		s.task = e.Task
+		s.provider = e.Provider
+		s.model = e.Model
+		s.maxCommands = e.MaxCommands
+	case logger.EventStepStarted:
+		s.step = e.Step
+	case logger.EventModelResponse:
+		if e.Error != "" {
+			return
+		}
+		s.thought = e.Thought
+		if !e.Cached {
+			s.usage.InputTokens += e.InputTokens
+			s.usage.OutputTokens += e.OutputTokens
+		}
+	case logger.EventCommandStarted:
+		s.history = append(s.history, entry{step: e.Step, command: e.Command, thought: e.Thought, risk: e.Risk, reasons: e.RiskReasons})
+		if s.follow {
+			s.selected = len(s.history) - 1
+		}
+	case logger.EventCommandOutput:
+		if last := s.last(); last != nil {
+			last.output += e.Data
+		}
+	case logger.EventCommandFinished:
+		if last := s.last(); last != nil {
+			if e.Output != "" {
+				last.output = e.Output
+			}
+			if e.ExitCode != nil {
+				last.exitCode = *e.ExitCode
+			}
+			last.err = e.Error
+			last.duration = e.Duration
+			last.done = true
+		}
+	case logger.EventLog:
+		s.message = e.Level + ": " + e.Message
+	case logger.EventTaskCompleted:
+		s.completed = true
+		s.result = e.Summary
+		s.outcome = e
+	case logger.EventRunFailed:
+		s.result = e.Error
+		s.outcome = e
+	}
+}
+
+func (s *state) last() *entry {
+	if len(s.history) == 0 {
+		return nil
+	}
+	return &s.history[len(s.history)-1]
+}